@@ -0,0 +1,101 @@
+{{/*
+Aliases of the built-in resource types, keyed by the resourceType name used in log entries.
+Kinds are the resourceType names in lowercase. Keep in sync with builtinResources in
+pkg/collector/names.go.
+*/}}
+{{- define "kube-state-logs.resourceAliases" -}}
+pod: [pods, po]
+container: [containers]
+service: [services, svc]
+node: [nodes, "no"]
+deployment: [deployments, deployments.apps, deploy]
+job: [jobs, jobs.batch]
+cronjob: [cronjobs, cronjobs.batch, cj]
+configmap: [configmaps, cm]
+secret: [secrets]
+persistentvolumeclaim: [persistentvolumeclaims, pvc]
+ingress: [ingresses, ingresses.networking.k8s.io, ing]
+horizontalpodautoscaler: [horizontalpodautoscalers, horizontalpodautoscalers.autoscaling, hpa]
+serviceaccount: [serviceaccounts, sa]
+endpoints: [ep]
+endpointslice: [endpointslices, endpointslices.discovery.k8s.io]
+persistentvolume: [persistentvolumes, pv]
+resourcequota: [resourcequotas, quota]
+poddisruptionbudget: [poddisruptionbudgets, poddisruptionbudgets.policy, pdb]
+storageclass: [storageclasses, storageclasses.storage.k8s.io, sc]
+networkpolicy: [networkpolicies, networkpolicies.networking.k8s.io, netpol]
+replicationcontroller: [replicationcontrollers, rc]
+limitrange: [limitranges, limits]
+lease: [leases, leases.coordination.k8s.io]
+role: [roles, roles.rbac.authorization.k8s.io]
+clusterrole: [clusterroles, clusterroles.rbac.authorization.k8s.io]
+rolebinding: [rolebindings, rolebindings.rbac.authorization.k8s.io]
+clusterrolebinding: [clusterrolebindings, clusterrolebindings.rbac.authorization.k8s.io]
+volumeattachment: [volumeattachments, volumeattachments.storage.k8s.io]
+certificatesigningrequest: [certificatesigningrequests, certificatesigningrequests.certificates.k8s.io, csr]
+namespace: [namespaces, ns]
+daemonset: [daemonsets, daemonsets.apps, ds]
+statefulset: [statefulsets, statefulsets.apps, sts]
+replicaset: [replicasets, replicasets.apps, rs]
+mutatingwebhookconfiguration: [mutatingwebhookconfigurations, mutatingwebhookconfigurations.admissionregistration.k8s.io]
+validatingwebhookconfiguration: [validatingwebhookconfigurations, validatingwebhookconfigurations.admissionregistration.k8s.io]
+ingressclass: [ingressclasses, ingressclasses.networking.k8s.io]
+priorityclass: [priorityclasses, priorityclasses.scheduling.k8s.io, pc]
+runtimeclass: [runtimeclasses, runtimeclasses.node.k8s.io]
+validatingadmissionpolicy: [validatingadmissionpolicies, validatingadmissionpolicies.admissionregistration.k8s.io]
+validatingadmissionpolicybinding: [validatingadmissionpolicybindings, validatingadmissionpolicybindings.admissionregistration.k8s.io]
+event: [events, events.events.k8s.io, ev]
+{{- end }}
+
+{{/*
+CRDs of the config file, or of config.crdConfigs without one, as a JSON list.
+*/}}
+{{- define "kube-state-logs.crds" -}}
+{{- if .Values.configFile }}
+{{- toJson (default (list) .Values.configFile.crds) }}
+{{- else }}
+{{- toJson (default (list) .Values.config.crdConfigs) }}
+{{- end }}
+{{- end }}
+
+{{/*
+Resource types of the config file, or of config.resources without one, as a JSON list of
+resourceType names. Names are resolved case-insensitively from plurals, group-qualified plurals,
+short names and kinds as kube-state-logs does, e.g. "pods", "po" and "Pod" to "pod". CRDs are
+referred to by their resource or <resource>.<group> name. Rendering fails on unknown names,
+which kube-state-logs would reject at startup.
+*/}}
+{{- define "kube-state-logs.resourceTypes" -}}
+{{- $aliases := dict }}
+{{- range $name, $names := include "kube-state-logs.resourceAliases" . | fromYaml }}
+{{- $_ := set $aliases $name $name }}
+{{- range $names }}
+{{- $_ := set $aliases . $name }}
+{{- end }}
+{{- end }}
+{{- range include "kube-state-logs.crds" . | fromJsonArray }}
+{{- $group := regexSplit "/" .apiVersion -1 | initial | join "/" }}
+{{- $name := ternary .resource (printf "%s.%s" .resource $group) (empty $group) }}
+{{- $_ := set $aliases (lower $name) $name }}
+{{- if not (hasKey $aliases (lower .resource)) }}
+{{- $_ := set $aliases (lower .resource) $name }}
+{{- end }}
+{{- end }}
+{{- $names := list }}
+{{- if .Values.configFile }}
+{{- range .Values.configFile.resources }}
+{{- $names = append $names .name }}
+{{- end }}
+{{- else }}
+{{- $names = .Values.config.resources | toStrings }}
+{{- end }}
+{{- $resourceTypes := list }}
+{{- range $names }}
+{{- $resourceType := get $aliases (lower (trim .)) }}
+{{- if not $resourceType }}
+{{- fail (printf "unknown resource type '%s' in resources; use a resourceType name such as pod or horizontalpodautoscaler, its plural, short name or kind, or a configured CRD" .) }}
+{{- end }}
+{{- $resourceTypes = append $resourceTypes $resourceType }}
+{{- end }}
+{{- toJson (uniq $resourceTypes) }}
+{{- end }}
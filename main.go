@@ -5,6 +5,7 @@ import (
 	"flag"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -12,17 +13,37 @@ import (
 
 	"go.goms.io/aks/kube-state-logs/pkg/collector"
 	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/metrics"
 )
 
 func main() {
 	// Parse command line flags
 	var (
 		logInterval     = flag.Duration("log-interval", 1*time.Minute, "Default interval between log outputs")
-		resources       = flag.String("resources", "pod,container,service,node,deployment,job,cronjob,configmap,secret,persistentvolumeclaim,ingress,horizontalpodautoscaler,serviceaccount,endpoints,persistentvolume,resourcequota,poddisruptionbudget,storageclass,networkpolicy,replicationcontroller,limitrange,lease,role,clusterrole,rolebinding,clusterrolebinding,volumeattachment,certificatesigningrequest,mutatingwebhookconfiguration,validatingwebhookconfiguration,ingressclass", "Comma-separated list of resources to monitor")
+		resources       = flag.String("resources", "pod,container,service,node,deployment,job,cronjob,configmap,secret,persistentvolumeclaim,ingress,horizontalpodautoscaler,serviceaccount,endpoints,endpointslice,persistentvolume,resourcequota,poddisruptionbudget,storageclass,networkpolicy,replicationcontroller,limitrange,lease,role,clusterrole,rolebinding,clusterrolebinding,volumeattachment,certificatesigningrequest,mutatingwebhookconfiguration,validatingwebhookconfiguration,ingressclass", "Comma-separated list of resources to monitor, by name, plural, short name or kind (e.g., 'pod,deployments,hpa')")
 		resourceConfigs = flag.String("resource-configs", "", "Comma-separated list of resource:interval pairs (e.g., 'deployments:5m,pods:1m,services:2m'). If not specified, uses log-interval for all resources.")
 		namespaces      = flag.String("namespaces", "", "Comma-separated list of namespaces to monitor (empty for all)")
+		eventResources  = flag.String("event-resources", "", "Comma-separated list of resources that also log an entry immediately on create, update and delete (e.g., 'pod,deployment')")
+		changeOnly      = flag.String("change-only-resources", "", "Comma-separated list of resources that only log entries whose content changed since the last tick (e.g., 'configmap,clusterrole,storageclass')")
+		heartbeatTicks  = flag.Int("heartbeat-ticks", 10, "For change-only resources, log every entry every N ticks so full state can be reconstructed (0 disables)")
+		crdConfigs      = flag.String("crd-configs", "", "Comma-separated list of CRD configurations (e.g., 'mygroup.example.com/v1:widgets:spec.size|spec.color'). Use 'widgets.mygroup.example.com' in resource-configs to set a CRD's interval.")
+		sinks           = flag.String("sinks", "stdout", "Comma-separated list of output sinks as [name=]type[?option=value&...] (e.g., 'stdout,security=file?path=/var/log/rbac.log&resources=role|clusterrole'). Sinks with a resources option only receive those resource types; the others receive every resource type not routed elsewhere.")
 		logLevel        = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
 		kubeconfig      = flag.String("kubeconfig", "", "Path to kubeconfig file (empty for in-cluster config)")
+		configFile      = flag.String("config", "", "Path to a YAML config file with the resources, intervals, namespaces, CRDs and sinks, replacing the corresponding flags. The file is reloaded when it changes or on SIGHUP.")
+		configReload    = flag.Duration("config-reload-interval", 10*time.Second, "How often to check the config file for changes")
+		leaderElect     = flag.Bool("leader-elect", false, "Elect a leader among replicas using a Lease. Only the leader logs entries; the other replicas keep warm caches and take over if it fails.")
+		leaseName       = flag.String("leader-elect-lease-name", "kube-state-logs", "Name of the Lease used for leader election, suffixed with -shard-<shard> when sharding so that every shard elects its own leader")
+		leaseNamespace  = flag.String("leader-elect-namespace", "", "Namespace of the Lease used for leader election (defaults to the pod's namespace)")
+		leaseDuration   = flag.Duration("leader-elect-lease-duration", 15*time.Second, "Time after which a standby replica takes over if the leader stops renewing the Lease")
+		renewDeadline   = flag.Duration("leader-elect-renew-deadline", 10*time.Second, "Time the leader keeps trying to renew the Lease before it stops logging")
+		retryPeriod     = flag.Duration("leader-elect-retry-period", 2*time.Second, "Interval between attempts to acquire or renew the Lease")
+		shard           = flag.Int("shard", 0, "Shard of this replica, from 0 to --total-shards minus 1")
+		totalShards     = flag.Int("total-shards", 1, "Number of replicas that objects are sharded across; each replica only caches and logs the objects of its own shard")
+		shardBy         = flag.String("shard-by", config.ShardByNamespace, "Key that objects are assigned to shards by: 'namespace', which keeps related objects together, or 'uid', which spreads them more evenly")
+		clusterShard    = flag.Int("cluster-scoped-shard", 0, "Shard that logs cluster-scoped objects such as nodes")
+		shardOrdinal    = flag.Bool("shard-from-ordinal", false, "Take --shard from the StatefulSet ordinal at the end of the pod name")
+		metricsAddr     = flag.String("metrics-addr", "", "Address to serve Prometheus metrics about collection and sinks on at /metrics, e.g. ':8080' (empty to disable)")
 	)
 	flag.Parse()
 
@@ -33,28 +54,88 @@ func main() {
 
 	klog.Info("Starting kube-state-logs...")
 
-	// Parse resource configurations
-	resourceConfigsList := config.ParseResourceConfigs(*resourceConfigs, *logInterval)
-
-	// If no specific resource configs provided, create default ones from resources list
-	if len(resourceConfigsList) == 0 {
-		resourcesList := config.ParseResourceList(*resources)
-		for _, resource := range resourcesList {
-			resourceConfigsList = append(resourceConfigsList, config.ResourceConfig{
-				Name:     resource,
-				Interval: *logInterval,
-			})
+	var cfg *config.Config
+	if *configFile != "" {
+		warnIgnoredFlags()
+
+		var err error
+		cfg, err = loadConfigFile(*configFile, *kubeconfig)
+		if err != nil {
+			klog.Fatalf("Failed to load config: %v", err)
+		}
+	} else {
+		// Parse resource configurations
+		resourceConfigsList, err := config.ParseResourceConfigs(*resourceConfigs, *logInterval)
+		if err != nil {
+			klog.Fatalf("Invalid resource configuration: %v", err)
+		}
+
+		// If no specific resource configs provided, create default ones from resources list
+		if len(resourceConfigsList) == 0 {
+			resourcesList := config.ParseResourceList(*resources)
+			for _, resource := range resourcesList {
+				resourceConfigsList = append(resourceConfigsList, config.ResourceConfig{
+					Name:     resource,
+					Interval: *logInterval,
+				})
+			}
+		}
+
+		// Parse CRD configurations
+		crdConfigList, err := config.ParseCRDConfigs(*crdConfigs)
+		if err != nil {
+			klog.Fatalf("Invalid CRD configuration: %v", err)
+		}
+
+		// Parse sink configurations
+		sinkConfigs, err := config.ParseSinkConfigs(*sinks)
+		if err != nil {
+			klog.Fatalf("Invalid sink configuration: %v", err)
+		}
+
+		// Create configuration
+		cfg = &config.Config{
+			LogInterval:     *logInterval,
+			Resources:       config.ParseResourceList(*resources),
+			ResourceConfigs: resourceConfigsList,
+			CRDs:            crdConfigList,
+			EventResources:  config.ParseResourceList(*eventResources),
+			ChangeOnly:      config.ParseResourceList(*changeOnly),
+			HeartbeatTicks:  *heartbeatTicks,
+			Sinks:           sinkConfigs,
+			Namespaces:      config.ParseNamespaceList(*namespaces),
+			Kubeconfig:      *kubeconfig,
 		}
 	}
 
-	// Create configuration
-	cfg := &config.Config{
-		LogInterval:     *logInterval,
-		Resources:       config.ParseResourceList(*resources),
-		ResourceConfigs: resourceConfigsList,
-		Namespaces:      config.ParseNamespaceList(*namespaces),
-		Kubeconfig:      *kubeconfig,
+	// Sharding is not part of the config file and cannot be reloaded
+	sharding := config.ShardConfig{Shard: *shard, TotalShards: *totalShards, By: *shardBy, ClusterScopedShard: *clusterShard}
+	if *shardOrdinal {
+		ordinal, err := config.ParseOrdinal(podName())
+		if err != nil {
+			klog.Fatalf("Failed to get shard: %v", err)
+		}
+		sharding.Shard = ordinal
 	}
+	cfg.Sharding = sharding
+
+	// Leader election is not part of the config file either
+	var leaderElection config.LeaderElectionConfig
+	if *leaderElect {
+		leaderElection = config.LeaderElectionConfig{
+			Enabled:       true,
+			LeaseName:     config.ShardLeaseName(*leaseName, sharding),
+			Namespace:     *leaseNamespace,
+			Identity:      podName(),
+			LeaseDuration: *leaseDuration,
+			RenewDeadline: *renewDeadline,
+			RetryPeriod:   *retryPeriod,
+		}
+		if leaderElection.Namespace == "" {
+			leaderElection.Namespace = podNamespace()
+		}
+	}
+	cfg.LeaderElection = leaderElection
 
 	// Create collector
 	collector, err := collector.New(cfg)
@@ -76,6 +157,28 @@ func main() {
 		cancel()
 	}()
 
+	// Serve metrics about kube-state-logs itself
+	if *metricsAddr != "" {
+		go func() {
+			if err := metrics.Serve(ctx, *metricsAddr); err != nil {
+				klog.Fatalf("Failed to serve metrics: %v", err)
+			}
+		}()
+	}
+
+	// Reload the config file when it changes or on SIGHUP
+	if *configFile != "" {
+		hupChan := make(chan os.Signal, 1)
+		signal.Notify(hupChan, syscall.SIGHUP)
+
+		go config.WatchFile(ctx, *configFile, *configReload, hupChan, func(updated *config.Config) error {
+			updated.Kubeconfig = *kubeconfig
+			updated.LeaderElection = leaderElection
+			updated.Sharding = sharding
+			return collector.Reload(updated)
+		})
+	}
+
 	// Start the collector
 	if err := collector.Run(ctx); err != nil {
 		klog.Fatalf("Collector failed: %v", err)
@@ -83,3 +186,45 @@ func main() {
 
 	klog.Info("kube-state-logs stopped")
 }
+
+// loadConfigFile loads and validates the config file
+func loadConfigFile(path, kubeconfig string) (*config.Config, error) {
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Kubeconfig = kubeconfig
+	klog.Infof("Loaded config file %s", path)
+	return cfg, nil
+}
+
+// warnIgnoredFlags warns about flags that are replaced by the config file
+func warnIgnoredFlags() {
+	replaced := map[string]bool{
+		"log-interval": true, "resources": true, "resource-configs": true, "namespaces": true, "event-resources": true,
+		"change-only-resources": true, "heartbeat-ticks": true, "crd-configs": true, "sinks": true,
+	}
+	flag.Visit(func(f *flag.Flag) {
+		if replaced[f.Name] {
+			klog.Warningf("Ignoring --%s, which is replaced by the config file", f.Name)
+		}
+	})
+}
+
+// podName returns the hostname, which is the pod name when running in a pod
+func podName() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		klog.Fatalf("Failed to get hostname: %v", err)
+	}
+	return hostname
+}
+
+// podNamespace returns the namespace of the pod's service account, or "default" outside a pod
+func podNamespace() string {
+	namespace, err := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
+	if err != nil {
+		return "default"
+	}
+	return strings.TrimSpace(string(namespace))
+}
@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// changeTracker remembers a fingerprint of every entry logged for a resource so that
+// unchanged entries can be skipped, with a full heartbeat every heartbeatTicks ticks
+type changeTracker struct {
+	heartbeatTicks int
+	ticks          int
+	fingerprints   map[string]string
+}
+
+// newChangeTracker creates a changeTracker. A heartbeatTicks of 0 disables heartbeats.
+func newChangeTracker(heartbeatTicks int) *changeTracker {
+	return &changeTracker{
+		heartbeatTicks: heartbeatTicks,
+		fingerprints:   make(map[string]string),
+	}
+}
+
+// filter returns the entries that are new or changed since the previous tick,
+// or all entries if this tick is a heartbeat
+func (t *changeTracker) filter(entries []any) ([]any, error) {
+	heartbeat := t.ticks == 0 || (t.heartbeatTicks > 0 && t.ticks%t.heartbeatTicks == 0)
+	t.ticks++
+
+	var changed []any
+	fingerprints := make(map[string]string, len(entries))
+
+	for _, entry := range entries {
+		key, fingerprint, err := fingerprintEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		// Objects that no longer exist drop out of the map so a re-created object is logged again
+		fingerprints[key] = fingerprint
+
+		if heartbeat || t.fingerprints[key] != fingerprint {
+			changed = append(changed, entry)
+		}
+	}
+
+	t.fingerprints = fingerprints
+	return changed, nil
+}
+
+// reset makes the next tick a heartbeat, e.g. after a standby replica takes over
+func (t *changeTracker) reset() {
+	t.ticks = 0
+}
+
+// fingerprintEntry returns an identity key and a content hash for an entry, ignoring its timestamp
+func fingerprintEntry(entry any) (string, string, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal entry: %w", err)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", "", fmt.Errorf("failed to unmarshal entry: %w", err)
+	}
+	delete(fields, "timestamp")
+
+	key := fmt.Sprintf("%v/%v/%v/%v", fields["resourceType"], fields["namespace"], fields["podName"], fields["name"])
+
+	// Maps are marshalled with sorted keys, so the hash is stable across ticks
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal entry: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
+
+	return key, hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,150 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+func configMapEntry(name string, tick int, dataKeys ...string) types.ConfigMapData {
+	return types.ConfigMapData{
+		LogEntryMetadata: types.LogEntryMetadata{
+			// Every tick gets a new timestamp, which must not count as a change
+			Timestamp:    time.Date(2024, 1, 1, 0, 0, tick, 0, time.UTC),
+			ResourceType: "configmap",
+			Name:         name,
+			Namespace:    "default",
+		},
+		DataKeys: dataKeys,
+	}
+}
+
+func TestChangeTracker_Filter(t *testing.T) {
+	type tick struct {
+		entries  []any
+		reset    bool
+		expected []string
+	}
+
+	tests := []struct {
+		name           string
+		heartbeatTicks int
+		ticks          []tick
+	}{
+		{
+			name:           "first tick always logs",
+			heartbeatTicks: 0,
+			ticks: []tick{
+				{entries: []any{configMapEntry("a", 0), configMapEntry("b", 0)}, expected: []string{"a", "b"}},
+			},
+		},
+		{
+			name:           "unchanged entries are skipped despite new timestamps",
+			heartbeatTicks: 0,
+			ticks: []tick{
+				{entries: []any{configMapEntry("a", 0, "x")}, expected: []string{"a"}},
+				{entries: []any{configMapEntry("a", 1, "x")}, expected: nil},
+				{entries: []any{configMapEntry("a", 2, "x", "y")}, expected: []string{"a"}},
+				{entries: []any{configMapEntry("a", 3, "x", "y")}, expected: nil},
+			},
+		},
+		{
+			name:           "no heartbeat when heartbeatTicks is 0",
+			heartbeatTicks: 0,
+			ticks: []tick{
+				{entries: []any{configMapEntry("a", 0)}, expected: []string{"a"}},
+				{entries: []any{configMapEntry("a", 1)}, expected: nil},
+				{entries: []any{configMapEntry("a", 2)}, expected: nil},
+				{entries: []any{configMapEntry("a", 3)}, expected: nil},
+			},
+		},
+		{
+			name:           "heartbeat every heartbeatTicks ticks",
+			heartbeatTicks: 2,
+			ticks: []tick{
+				{entries: []any{configMapEntry("a", 0)}, expected: []string{"a"}},
+				{entries: []any{configMapEntry("a", 1)}, expected: nil},
+				{entries: []any{configMapEntry("a", 2)}, expected: []string{"a"}},
+				{entries: []any{configMapEntry("a", 3)}, expected: nil},
+				{entries: []any{configMapEntry("a", 4)}, expected: []string{"a"}},
+			},
+		},
+		{
+			name:           "re-created entry under the same key is logged again",
+			heartbeatTicks: 0,
+			ticks: []tick{
+				{entries: []any{configMapEntry("a", 0), configMapEntry("b", 0)}, expected: []string{"a", "b"}},
+				{entries: []any{configMapEntry("b", 1)}, expected: nil},
+				{entries: []any{configMapEntry("a", 2), configMapEntry("b", 2)}, expected: []string{"a"}},
+			},
+		},
+		{
+			name:           "reset after standby makes the next tick a heartbeat",
+			heartbeatTicks: 0,
+			ticks: []tick{
+				{entries: []any{configMapEntry("a", 0)}, expected: []string{"a"}},
+				{entries: []any{configMapEntry("a", 1)}, expected: nil},
+				{entries: []any{configMapEntry("a", 2)}, reset: true, expected: []string{"a"}},
+				{entries: []any{configMapEntry("a", 3)}, expected: nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newChangeTracker(tt.heartbeatTicks)
+			for i, tick := range tt.ticks {
+				if tick.reset {
+					tracker.reset()
+				}
+				changed, err := tracker.filter(tick.entries)
+				if err != nil {
+					t.Fatalf("Expected no error on tick %d, got %v", i, err)
+				}
+
+				var names []string
+				for _, entry := range changed {
+					names = append(names, entry.(types.ConfigMapData).Name)
+				}
+				if !reflect.DeepEqual(names, tick.expected) {
+					t.Errorf("Expected %v on tick %d, got %v", tick.expected, i, names)
+				}
+			}
+		})
+	}
+}
+
+func TestFingerprintEntry(t *testing.T) {
+	key, fingerprint, err := fingerprintEntry(configMapEntry("a", 0, "x"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if key != "configmap/default/<nil>/a" {
+		t.Errorf("Expected key 'configmap/default/<nil>/a', got '%s'", key)
+	}
+
+	tests := []struct {
+		name  string
+		entry any
+		same  bool
+	}{
+		{name: "different timestamp", entry: configMapEntry("a", 30, "x"), same: true},
+		{name: "different data keys", entry: configMapEntry("a", 0, "y"), same: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			otherKey, other, err := fingerprintEntry(tt.entry)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if otherKey != key {
+				t.Errorf("Expected key '%s', got '%s'", key, otherKey)
+			}
+			if (other == fingerprint) != tt.same {
+				t.Errorf("Expected same fingerprint to be %v, got %v", tt.same, other == fingerprint)
+			}
+		})
+	}
+}
@@ -4,28 +4,51 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/klog/v2"
 
 	"go.goms.io/aks/kube-state-logs/pkg/collector/resources"
 	"go.goms.io/aks/kube-state-logs/pkg/config"
 	"go.goms.io/aks/kube-state-logs/pkg/interfaces"
+	"go.goms.io/aks/kube-state-logs/pkg/metrics"
 )
 
 // Collector handles the collection and logging of Kubernetes resource state
 type Collector struct {
-	config   *config.Config
-	client   *kubernetes.Clientset
-	logger   interfaces.Logger
-	handlers map[string]interfaces.ResourceHandler
-	factory  informers.SharedInformerFactory
-	stopCh   chan struct{}
-	wg       sync.WaitGroup
+	client        *kubernetes.Clientset
+	dynamicClient dynamic.Interface
+	logger        *switchingLogger
+
+	// leading is set while this replica logs entries; always set without leader election
+	leading atomic.Bool
+	elector *leaderelection.LeaderElector // nil without leader election
+	shard   *shardFilter                  // nil without sharding
+
+	// reloadMu serializes reloads, which only hold mu while they swap the fields below
+	reloadMu sync.Mutex
+	// mu serializes Run and Reload and guards the fields below
+	mu        sync.Mutex
+	config    *config.Config
+	informers *informerSet
+	tickers   *tickerSet
+	ctx       context.Context // Context passed to Run, nil until the collector runs
+}
+
+// handlerFactory adapts a handler constructor to the builtinResource signature
+func handlerFactory[H interfaces.ResourceHandler](newHandler func(kubernetes.Interface) H) func(kubernetes.Interface) interfaces.ResourceHandler {
+	return func(client kubernetes.Interface) interfaces.ResourceHandler {
+		return newHandler(client)
+	}
 }
 
 // New creates a new Collector instance
@@ -55,142 +78,312 @@ func New(cfg *config.Config) (*Collector, error) {
 		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
 	}
 
-	// Create logger
-	logger := NewLogger()
+	dynamicClient, err := dynamic.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	if err := cfg.Sharding.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid sharding configuration: %w", err)
+	}
 
-	// Create shared informer factory with no resync (0 means no resync)
-	factory := informers.NewSharedInformerFactory(client, 0)
+	// Resolve plural, short and kind names of resource types to their handlers
+	cfg, err = resolveConfig(cfg, newNameResolver(client.Discovery(), cfg.CRDs))
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := newEntryFilter(cfg.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create logger that routes entries to the configured sinks
+	logger, err := newSwitchingLogger(cfg.Sinks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sinks: %w", err)
+	}
+	logger.filter.Store(filter)
 
-	// Create collector
 	c := &Collector{
-		config:   cfg,
-		client:   client,
-		logger:   logger,
-		handlers: make(map[string]interfaces.ResourceHandler),
-		factory:  factory,
-		stopCh:   make(chan struct{}),
+		config:        cfg,
+		client:        client,
+		dynamicClient: dynamicClient,
+		logger:        logger,
 	}
 
-	// Register resource handlers
-	c.registerHandlers()
+	if cfg.Sharding.Enabled() {
+		klog.Infof("Logging shard %d of %d, sharded by %s", cfg.Sharding.Shard, cfg.Sharding.TotalShards, cfg.Sharding.By)
+		c.shard = &shardFilter{ShardConfig: cfg.Sharding}
+	}
+
+	if cfg.LeaderElection.Enabled {
+		c.elector, err = c.newLeaderElector(client, cfg.LeaderElection)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		c.setLeading(true)
+	}
 
 	return c, nil
 }
 
-// registerHandlers registers all available resource handlers
-func (c *Collector) registerHandlers() {
-	// Register resource handlers
-	handlers := map[string]interfaces.ResourceHandler{
-		"pod":                              resources.NewPodHandler(c.client),
-		"container":                        resources.NewContainerHandler(c.client),
-		"service":                          resources.NewServiceHandler(c.client),
-		"node":                             resources.NewNodeHandler(c.client),
-		"deployment":                       resources.NewDeploymentHandler(c.client),
-		"job":                              resources.NewJobHandler(c.client),
-		"cronjob":                          resources.NewCronJobHandler(c.client),
-		"configmap":                        resources.NewConfigMapHandler(c.client),
-		"secret":                           resources.NewSecretHandler(c.client),
-		"persistentvolumeclaim":            resources.NewPersistentVolumeClaimHandler(c.client),
-		"ingress":                          resources.NewIngressHandler(c.client),
-		"horizontalpodautoscaler":          resources.NewHorizontalPodAutoscalerHandler(c.client),
-		"serviceaccount":                   resources.NewServiceAccountHandler(c.client),
-		"endpoints":                        resources.NewEndpointsHandler(c.client),
-		"persistentvolume":                 resources.NewPersistentVolumeHandler(c.client),
-		"resourcequota":                    resources.NewResourceQuotaHandler(c.client),
-		"poddisruptionbudget":              resources.NewPodDisruptionBudgetHandler(c.client),
-		"storageclass":                     resources.NewStorageClassHandler(c.client),
-		"networkpolicy":                    resources.NewNetworkPolicyHandler(c.client),
-		"replicationcontroller":            resources.NewReplicationControllerHandler(c.client),
-		"limitrange":                       resources.NewLimitRangeHandler(c.client),
-		"lease":                            resources.NewLeaseHandler(c.client),
-		"role":                             resources.NewRoleHandler(c.client),
-		"clusterrole":                      resources.NewClusterRoleHandler(c.client),
-		"rolebinding":                      resources.NewRoleBindingHandler(c.client),
-		"clusterrolebinding":               resources.NewClusterRoleBindingHandler(c.client),
-		"volumeattachment":                 resources.NewVolumeAttachmentHandler(c.client),
-		"certificatesigningrequest":        resources.NewCertificateSigningRequestHandler(c.client),
-		"namespace":                        resources.NewNamespaceHandler(c.client),
-		"daemonset":                        resources.NewDaemonSetHandler(c.client),
-		"statefulset":                      resources.NewStatefulSetHandler(c.client),
-		"replicaset":                       resources.NewReplicaSetHandler(c.client),
-		"mutatingwebhookconfiguration":     resources.NewMutatingWebhookConfigurationHandler(c.client),
-		"validatingwebhookconfiguration":   resources.NewValidatingWebhookConfigurationHandler(c.client),
-		"ingressclass":                     resources.NewIngressClassHandler(c.client),
-		"priorityclass":                    resources.NewPriorityClassHandler(c.client),
-		"runtimeclass":                     resources.NewRuntimeClassHandler(c.client),
-		"validatingadmissionpolicy":        resources.NewValidatingAdmissionPolicyHandler(c.client),
-		"validatingadmissionpolicybinding": resources.NewValidatingAdmissionPolicyBindingHandler(c.client),
-	}
-
-	for resourceName, handler := range handlers {
-		c.handlers[resourceName] = handler
-	}
+// informerSet holds the handlers of a configuration and the informers they read from,
+// which are started and stopped together
+type informerSet struct {
+	handlers       map[string]interfaces.ResourceHandler
+	crdHandlers    map[string]*resources.CRDHandler
+	factory        informers.SharedInformerFactory
+	dynamicFactory dynamicinformer.DynamicSharedInformerFactory
+	logger         *gatedLogger // Logger of informer events
+	stopCh         chan struct{}
 }
 
-// Run starts the informers and collection loop
-func (c *Collector) Run(ctx context.Context) error {
-	klog.Info("Starting kube-state-logs with individual tickers...")
+// newInformerSet creates the handlers of the configured resources and CRDs
+func (c *Collector) newInformerSet(cfg *config.Config) *informerSet {
+	s := &informerSet{
+		handlers:    make(map[string]interfaces.ResourceHandler),
+		crdHandlers: make(map[string]*resources.CRDHandler),
+		// Create shared informer factories with no resync (0 means no resync)
+		factory:        informers.NewSharedInformerFactoryWithOptions(c.client, 0, c.shard.informerFactoryOptions()...),
+		dynamicFactory: dynamicinformer.NewDynamicSharedInformerFactory(c.dynamicClient, 0),
+		logger:         &gatedLogger{next: c.logger, leading: &c.leading},
+		stopCh:         make(chan struct{}),
+	}
+	if c.shard != nil {
+		s.dynamicFactory = shardedDynamicFactory{DynamicSharedInformerFactory: s.dynamicFactory, filter: c.shard}
+	}
 
-	// Setup informers for each configured resource type
-	for _, resourceType := range c.config.Resources {
-		handler, exists := c.handlers[resourceType]
+	for _, resourceType := range cfg.Resources {
+		resource, exists := builtinResources[resourceType]
 		if !exists {
 			klog.Warningf("No handler found for resource type: %s", resourceType)
 			continue
 		}
+		s.handlers[resourceType] = resource.newHandler(c.client)
+	}
 
-		// Setup informer with no resync period
-		if err := handler.SetupInformer(c.factory, c.logger, 0); err != nil {
+	// Register one CRD handler per configured custom resource
+	for _, crdConfig := range cfg.CRDs {
+		gvr, err := crdConfig.GroupVersionResource()
+		if err != nil {
+			klog.Errorf("Skipping CRD config: %v", err)
+			continue
+		}
+
+		name := crdConfig.Name()
+		if _, exists := s.crdHandlers[name]; exists {
+			klog.Warningf("Duplicate CRD config for %s, ignoring", name)
+			continue
+		}
+
+		s.crdHandlers[name] = resources.NewCRDHandler(c.dynamicClient, gvr, name, crdConfig.CustomFields)
+	}
+
+	return s
+}
+
+// getCollector returns the built-in or CRD handler registered under the given name
+func (s *informerSet) getCollector(resourceName string) (interfaces.ResourceCollector, bool) {
+	if handler, exists := s.handlers[resourceName]; exists {
+		return handler, true
+	}
+	if handler, exists := s.crdHandlers[resourceName]; exists {
+		return handler, true
+	}
+	return nil, false
+}
+
+// start sets up the informers and waits until they are synced, giving up when ctx is done
+// or, if it is positive, after timeout
+func (s *informerSet) start(ctx context.Context, cfg *config.Config, timeout time.Duration) error {
+	// Setup informers for each configured resource type with no resync period
+	for resourceType, handler := range s.handlers {
+		if err := handler.SetupInformer(s.factory, s.logger, 0); err != nil {
 			klog.Errorf("Failed to setup informer for %s: %v", resourceType, err)
 			continue
 		}
+
+		enableEventLogging(cfg, resourceType, handler)
+	}
+
+	// Setup dynamic informers for each configured CRD
+	for name, handler := range s.crdHandlers {
+		if err := handler.SetupInformer(s.dynamicFactory, s.logger, 0); err != nil {
+			klog.Errorf("Failed to setup informer for CRD %s: %v", name, err)
+			continue
+		}
+
+		enableEventLogging(cfg, name, handler)
 	}
 
-	// Start the informer factory
-	c.factory.Start(c.stopCh)
+	// Start the informer factories
+	s.factory.Start(s.stopCh)
+	s.dynamicFactory.Start(s.stopCh)
+
+	waitCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
 	// Wait for all informers to sync
 	klog.Info("Waiting for informers to sync...")
-	synced := c.factory.WaitForCacheSync(c.stopCh)
+	synced := s.factory.WaitForCacheSync(waitCtx.Done())
 	for resourceType, isSynced := range synced {
 		if !isSynced {
 			return fmt.Errorf("failed to sync informer for %v", resourceType)
 		}
 	}
 
+	dynamicSynced := s.dynamicFactory.WaitForCacheSync(waitCtx.Done())
+	for gvr, isSynced := range dynamicSynced {
+		if !isSynced {
+			return fmt.Errorf("failed to sync informer for %v", gvr)
+		}
+	}
+
+	// Objects that were logged before a restart or reload are not logged again
+	for _, handler := range s.handlers {
+		if deduplicating, ok := handler.(interfaces.DeduplicatingCollector); ok {
+			deduplicating.MarkSeen(cfg.Namespaces)
+		}
+	}
+
 	klog.Info("All informers synced successfully")
+	return nil
+}
+
+// stop stops the informers and waits for them to exit
+func (s *informerSet) stop() {
+	s.logger.enabled.Store(false)
+	close(s.stopCh)
+	s.factory.Shutdown()
+	s.dynamicFactory.Shutdown()
+}
+
+// Run starts the informers and collection loop
+func (c *Collector) Run(ctx context.Context) error {
+	klog.Info("Starting kube-state-logs with individual tickers...")
+
+	c.mu.Lock()
+	informers := c.newInformerSet(c.config)
+	informers.logger.enabled.Store(true)
+	if err := informers.start(ctx, c.config, 0); err != nil {
+		informers.stop()
+		c.mu.Unlock()
+		return err
+	}
+	c.informers = informers
+	c.ctx = ctx
 
 	// Start individual tickers for each resource
-	c.startResourceTickers(ctx)
+	c.tickers = c.startResourceTickers(ctx, c.config, informers)
+	c.mu.Unlock()
+
+	// Contend for the lease once the caches are synced, so a new leader logs complete state
+	electionDone := make(chan struct{})
+	if c.elector != nil {
+		klog.Infof("Waiting to acquire lease %s/%s...", c.config.LeaderElection.Namespace, c.config.LeaderElection.LeaseName)
+		go func() {
+			defer close(electionDone)
+			c.runLeaderElection(ctx)
+		}()
+	} else {
+		close(electionDone)
+	}
 
 	// Wait for context cancellation
 	<-ctx.Done()
-	close(c.stopCh)
-	c.wg.Wait()
+	c.mu.Lock()
+	c.tickers.stop()
+	c.informers.stop()
+	c.mu.Unlock()
+	// Wait for the lease to be released
+	<-electionDone
+	c.closeLogger()
 	return ctx.Err()
 }
 
+// closeLogger closes the logger's sinks once nothing writes to them anymore
+func (c *Collector) closeLogger() {
+	if err := c.logger.Close(); err != nil {
+		klog.Errorf("Failed to close sinks: %v", err)
+	}
+}
+
+// enableEventLogging registers informer event handlers that log tombstones for deleted objects,
+// plus create and update entries for resources configured as event-driven
+func enableEventLogging(cfg *config.Config, resourceName string, handler any) {
+	eventHandler, ok := handler.(interfaces.EventDrivenHandler)
+	if !ok {
+		if cfg.IsEventDriven(resourceName) {
+			klog.Warningf("Resource type %s does not support event-driven logging", resourceName)
+		}
+		return
+	}
+
+	if err := eventHandler.EnableDeletionLogging(cfg.Namespaces); err != nil {
+		klog.Errorf("Failed to enable deletion logging for %s: %v", resourceName, err)
+	}
+
+	if !cfg.IsEventDriven(resourceName) {
+		return
+	}
+
+	if err := eventHandler.EnableEventLogging(cfg.Namespaces); err != nil {
+		klog.Errorf("Failed to enable event-driven logging for %s: %v", resourceName, err)
+		return
+	}
+
+	klog.Infof("Enabled event-driven logging for %s", resourceName)
+}
+
+// tickerSet is a set of running resource tickers, stopped together
+type tickerSet struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// stop stops the tickers and waits for running collections to finish
+func (t *tickerSet) stop() {
+	t.cancel()
+	t.wg.Wait()
+}
+
 // startResourceTickers starts individual tickers for each resource based on their configured intervals
-func (c *Collector) startResourceTickers(ctx context.Context) {
+func (c *Collector) startResourceTickers(ctx context.Context, cfg *config.Config, informers *informerSet) *tickerSet {
+	ctx, cancel := context.WithCancel(ctx)
+	tickers := &tickerSet{cancel: cancel}
+
 	// Create a map of resource names to their intervals
 	resourceIntervals := make(map[string]time.Duration)
 
 	// First, populate with specific resource configs
-	for _, resourceConfig := range c.config.ResourceConfigs {
+	for _, resourceConfig := range cfg.ResourceConfigs {
 		resourceIntervals[resourceConfig.Name] = resourceConfig.Interval
 	}
 
 	// Then, ensure all resources in the Resources list have an interval (use default if not specified)
-	for _, resourceName := range c.config.Resources {
+	for _, resourceName := range cfg.Resources {
 		if _, exists := resourceIntervals[resourceName]; !exists {
-			resourceIntervals[resourceName] = c.config.LogInterval
+			resourceIntervals[resourceName] = cfg.LogInterval
+		}
+	}
+
+	// Every configured CRD is collected, using its resource config interval if one was given
+	for name := range informers.crdHandlers {
+		if _, exists := resourceIntervals[name]; !exists {
+			resourceIntervals[name] = cfg.LogInterval
 		}
 	}
 
 	// Start tickers for all resources
 	for resourceName, interval := range resourceIntervals {
 		// Check if we have a handler for this resource
-		handler, exists := c.handlers[resourceName]
+		handler, exists := informers.getCollector(resourceName)
 		if !exists {
 			klog.Warningf("No handler found for resource type: %s", resourceName)
 			continue
@@ -198,42 +391,105 @@ func (c *Collector) startResourceTickers(ctx context.Context) {
 
 		klog.Infof("Starting ticker for %s with interval %v", resourceName, interval)
 
-		c.wg.Add(1)
-		go func(name string, tickerInterval time.Duration, h interfaces.ResourceHandler) {
-			defer c.wg.Done()
+		var tracker *changeTracker
+		if cfg.IsChangeOnly(resourceName) {
+			klog.Infof("Logging only changed %s entries, with a heartbeat every %d ticks", resourceName, cfg.HeartbeatTicks)
+			tracker = newChangeTracker(cfg.HeartbeatTicks)
+		}
+
+		tickers.wg.Add(1)
+		go func(name string, tickerInterval time.Duration, h interfaces.ResourceCollector) {
+			defer tickers.wg.Done()
+
+			deduplicating, _ := h.(interfaces.DeduplicatingCollector)
 
 			ticker := time.NewTicker(tickerInterval)
 			defer ticker.Stop()
 
+			standby := false
 			for {
 				select {
 				case <-ctx.Done():
 					return
 				case <-ticker.C:
-					if err := c.collectAndLogResource(ctx, name, h); err != nil {
+					// Standby replicas skip ticks and log all entries once they take over, except
+					// for objects the leader already logged once
+					if !c.leading.Load() {
+						standby = true
+						if deduplicating != nil {
+							deduplicating.MarkSeen(cfg.Namespaces)
+						}
+						continue
+					}
+					if standby && tracker != nil {
+						tracker.reset()
+					}
+					standby = false
+					if err := c.collectAndLogResource(ctx, name, h, cfg.Namespaces, tracker); err != nil && ctx.Err() == nil {
 						klog.Errorf("Collection failed for %s: %v", name, err)
 					}
 				}
 			}
 		}(resourceName, interval, handler)
 	}
+
+	return tickers
+}
+
+// informerHandler is implemented by handlers that collect from an informer cache
+type informerHandler interface {
+	GetInformer() cache.SharedIndexInformer
 }
 
-// collectAndLogResource collects and logs data for a specific resource
-func (c *Collector) collectAndLogResource(ctx context.Context, resourceName string, handler interfaces.ResourceHandler) error {
-	entries, err := handler.Collect(ctx, c.config.Namespaces)
+// collectAndLogResource collects and logs data for a specific resource, dropping unchanged
+// entries if it has a change tracker
+func (c *Collector) collectAndLogResource(ctx context.Context, resourceName string, handler interfaces.ResourceCollector, namespaces []string, tracker *changeTracker) error {
+	start := time.Now()
+	defer func() {
+		metrics.CollectionDuration.WithLabelValues(resourceName).Observe(time.Since(start).Seconds())
+	}()
+
+	if cached, ok := handler.(informerHandler); ok {
+		metrics.InformerCacheObjects.WithLabelValues(resourceName).Set(float64(len(cached.GetInformer().GetStore().ListKeys())))
+	}
+
+	entries, err := handler.Collect(ctx, namespaces)
 	if err != nil {
+		metrics.CollectionErrors.WithLabelValues(resourceName).Inc()
 		return fmt.Errorf("failed to collect %s: %w", resourceName, err)
 	}
 
-	// Log all collected entries
+	collected := len(entries)
+
+	// Drop unchanged entries for change-only resources
+	if tracker != nil {
+		entries, err = tracker.filter(entries)
+		if err != nil {
+			metrics.CollectionErrors.WithLabelValues(resourceName).Inc()
+			return fmt.Errorf("failed to detect changes for %s: %w", resourceName, err)
+		}
+	}
+
+	// Log all collected entries, giving up if the tickers are stopped while a sink is full
+	failed := 0
 	for _, entry := range entries {
-		if err := c.logger.Log(entry); err != nil {
+		if err := c.logger.LogContext(ctx, entry); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			klog.Errorf("Failed to log entry for %s: %v", resourceName, err)
+			failed++
 		}
 	}
 
-	klog.V(2).Infof("Collected and logged %d entries for %s", len(entries), resourceName)
+	// The collection only succeeded if its entries reached the sinks
+	if failed > 0 && failed == len(entries) {
+		metrics.CollectionErrors.WithLabelValues(resourceName).Inc()
+		return fmt.Errorf("failed to log any of the %d entries for %s", failed, resourceName)
+	}
+
+	metrics.LastSuccessfulCollection.WithLabelValues(resourceName).SetToCurrentTime()
+	klog.V(2).Infof("Collected %d and logged %d entries for %s", collected, len(entries)-failed, resourceName)
 	return nil
 }
 
@@ -244,7 +500,7 @@ func (c *Collector) collectAndLog(ctx context.Context) error {
 
 	// Collect from each configured resource type
 	for _, resourceType := range c.config.Resources {
-		handler, exists := c.handlers[resourceType]
+		handler, exists := c.informers.getCollector(resourceType)
 		if !exists {
 			klog.Warningf("No handler found for resource type: %s", resourceType)
 			continue
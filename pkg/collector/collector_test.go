@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"go.goms.io/aks/kube-state-logs/pkg/collector/testutils"
+	"go.goms.io/aks/kube-state-logs/pkg/interfaces"
+	"go.goms.io/aks/kube-state-logs/pkg/metrics"
+	"go.goms.io/aks/kube-state-logs/pkg/sinks"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// staticCollector returns the same entries on every collection
+type staticCollector struct {
+	entries []any
+}
+
+func (s *staticCollector) Collect(ctx context.Context, namespaces []string) ([]any, error) {
+	return s.entries, nil
+}
+
+// failingLogger fails to log every entry
+type failingLogger struct{}
+
+func (failingLogger) Log(entry any) error {
+	return errors.New("sink unavailable")
+}
+
+func TestCollector_CollectAndLogResource_Metrics(t *testing.T) {
+	entries := []any{
+		types.PodData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "pod", Name: "web-0"}},
+		types.PodData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "pod", Name: "web-1"}},
+	}
+
+	tests := []struct {
+		name          string
+		sink          interfaces.Logger
+		entries       []any
+		expectSuccess bool
+	}{
+		{name: "logged", sink: &testutils.MockLogger{}, entries: entries, expectSuccess: true},
+		{name: "nothing to log", sink: failingLogger{}, entries: nil, expectSuccess: true},
+		{name: "every entry failed", sink: failingLogger{}, entries: entries, expectSuccess: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := "test-" + tt.name
+			router := sinks.NewRouter()
+			router.AddSink(tt.sink, nil)
+			c := &Collector{logger: &switchingLogger{current: router}}
+
+			err := c.collectAndLogResource(context.Background(), resource, &staticCollector{entries: tt.entries}, nil, nil)
+			if tt.expectSuccess && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if !tt.expectSuccess && err == nil {
+				t.Error("Expected an error")
+			}
+
+			lastSuccess := testutil.ToFloat64(metrics.LastSuccessfulCollection.WithLabelValues(resource))
+			if tt.expectSuccess && lastSuccess == 0 {
+				t.Error("Expected the last successful collection to be set")
+			}
+			if !tt.expectSuccess && lastSuccess != 0 {
+				t.Errorf("Expected the last successful collection not to be set, got %v", lastSuccess)
+			}
+			expectedErrors := 0.0
+			if !tt.expectSuccess {
+				expectedErrors = 1
+			}
+			if collectionErrors := testutil.ToFloat64(metrics.CollectionErrors.WithLabelValues(resource)); collectionErrors != expectedErrors {
+				t.Errorf("Expected %v collection errors, got %v", expectedErrors, collectionErrors)
+			}
+		})
+	}
+}
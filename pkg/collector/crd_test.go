@@ -0,0 +1,138 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"go.goms.io/aks/kube-state-logs/pkg/collector/testutils"
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/sinks"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+func TestCollector_CRDs(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	widget := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]any{"name": "widget-1", "namespace": "default"},
+		"spec":       map[string]any{"size": "large", "color": "blue"},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "WidgetList"}, widget)
+
+	logger := &testutils.MockLogger{}
+	router := sinks.NewRouter()
+	router.AddSink(logger, nil)
+	c := &Collector{dynamicClient: dynamicClient, logger: &switchingLogger{current: router}}
+	c.leading.Store(true)
+
+	cfg := &config.Config{
+		LogInterval: time.Hour,
+		CRDs: []config.CRDConfig{
+			{APIVersion: "example.com/v1", Resource: "widgets", CustomFields: []string{"spec.size"}},
+			// Duplicates and malformed configs are skipped
+			{APIVersion: "example.com/v1", Resource: "widgets"},
+			{APIVersion: "example.com/v1/widgets", Resource: "gadgets"},
+		},
+		ResourceConfigs: []config.ResourceConfig{{Name: "widgets.example.com", Interval: 10 * time.Millisecond}},
+	}
+
+	informers := c.newInformerSet(cfg)
+	if len(informers.crdHandlers) != 1 {
+		t.Fatalf("Expected one CRD handler, got %d", len(informers.crdHandlers))
+	}
+	if _, exists := informers.getCollector("widgets.example.com"); !exists {
+		t.Fatal("Expected a collector for widgets.example.com")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := informers.start(ctx, cfg, 5*time.Second); err != nil {
+		t.Fatalf("Expected informers to sync, got %v", err)
+	}
+	defer informers.stop()
+
+	tickers := c.startResourceTickers(ctx, cfg, informers)
+	defer tickers.stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(logger.GetLogs()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the CRD ticker to log an entry")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	entry, ok := logger.GetLogs()[0].(types.CRDData)
+	if !ok {
+		t.Fatalf("Expected a CRDData entry, got %T", logger.GetLogs()[0])
+	}
+	if entry.Name != "widget-1" || entry.Namespace != "default" || entry.Kind != "Widget" {
+		t.Errorf("Expected default/widget-1 of kind Widget, got %s/%s of kind %s", entry.Namespace, entry.Name, entry.Kind)
+	}
+	if len(entry.CustomFields) != 1 || entry.CustomFields["spec.size"] != "large" {
+		t.Errorf("Expected custom field spec.size=large only, got %v", entry.CustomFields)
+	}
+}
+
+func TestCollector_CRDRoutes(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	widget := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]any{"name": "widget-1", "namespace": "default"},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "WidgetList"}, widget)
+
+	// The sink route is resolved from the plural name to <resource>.<group>
+	crds := []config.CRDConfig{{APIVersion: "example.com/v1", Resource: "widgets"}}
+	cfg, err := resolveConfig(&config.Config{
+		LogInterval: 10 * time.Millisecond,
+		CRDs:        crds,
+		Sinks:       []config.SinkConfig{{Name: "widgets", Type: "stdout", Resources: []string{"widgets"}}},
+	}, newNameResolver(nil, crds))
+	if err != nil {
+		t.Fatalf("Expected the config to resolve, got %v", err)
+	}
+
+	routed := &testutils.MockLogger{}
+	other := &testutils.MockLogger{}
+	router := sinks.NewRouter()
+	router.AddSink(routed, cfg.Sinks[0].Resources)
+	router.AddSink(other, nil)
+	c := &Collector{dynamicClient: dynamicClient, logger: &switchingLogger{current: router}}
+	c.leading.Store(true)
+
+	informers := c.newInformerSet(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := informers.start(ctx, cfg, 5*time.Second); err != nil {
+		t.Fatalf("Expected informers to sync, got %v", err)
+	}
+	defer informers.stop()
+
+	tickers := c.startResourceTickers(ctx, cfg, informers)
+	defer tickers.stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(routed.GetLogs()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the CRD entry to be routed to the widgets sink")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	entry := routed.GetLogs()[0].(types.CRDData)
+	if entry.ResourceType != "widgets.example.com" {
+		t.Errorf("Expected resource type 'widgets.example.com', got '%s'", entry.ResourceType)
+	}
+	if logs := other.GetLogs(); len(logs) != 0 {
+		t.Errorf("Expected no entries in the default sink, got %d", len(logs))
+	}
+}
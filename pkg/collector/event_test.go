@@ -0,0 +1,132 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go.goms.io/aks/kube-state-logs/pkg/collector/resources"
+	"go.goms.io/aks/kube-state-logs/pkg/collector/testutils"
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/interfaces"
+	"go.goms.io/aks/kube-state-logs/pkg/sinks"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// newEventInformerSet creates an informerSet with an event handler reading from client, as
+// newInformerSet would for a configuration with the event resource
+func newEventInformerSet(c *Collector, client kubernetes.Interface) *informerSet {
+	return &informerSet{
+		handlers:       map[string]interfaces.ResourceHandler{"event": resources.NewEventHandler(client)},
+		factory:        informers.NewSharedInformerFactory(client, 0),
+		dynamicFactory: dynamicinformer.NewDynamicSharedInformerFactory(dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()), 0),
+		logger:         &gatedLogger{next: c.logger, leading: &c.leading},
+		stopCh:         make(chan struct{}),
+	}
+}
+
+func createEvent(t *testing.T, client kubernetes.Interface, name string) {
+	t.Helper()
+	event := &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: k8stypes.UID("uid-" + name)},
+		EventTime:  metav1.NowMicro(),
+		Reason:     "BackOff",
+	}
+	if _, err := client.EventsV1().Events("default").Create(context.Background(), event, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+}
+
+// waitForEvents waits until the informers have cached count events, then lets a few ticks pass
+func waitForEvents(t *testing.T, informers *informerSet, count int) {
+	t.Helper()
+	handler := informers.handlers["event"].(*resources.EventHandler)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(handler.GetInformer().GetStore().ListKeys()) < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d cached events", count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+}
+
+func loggedEventNames(logger *testutils.MockLogger) []string {
+	var names []string
+	for _, entry := range logger.GetLogs() {
+		if event, ok := entry.(types.EventData); ok {
+			names = append(names, event.Name)
+		}
+	}
+	return names
+}
+
+func TestCollector_EventsLoggedOnce(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	createEvent(t, client, "before-start")
+
+	logger := &testutils.MockLogger{}
+	router := sinks.NewRouter()
+	router.AddSink(logger, nil)
+	c := &Collector{logger: &switchingLogger{current: router}}
+	c.leading.Store(true)
+
+	cfg := &config.Config{
+		LogInterval: 10 * time.Millisecond,
+		Resources:   []string{"event"},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Events that exist when the informers sync were logged before the restart
+	current := newEventInformerSet(c, client)
+	if err := current.start(ctx, cfg, 5*time.Second); err != nil {
+		t.Fatalf("Expected informers to sync, got %v", err)
+	}
+	tickers := c.startResourceTickers(ctx, cfg, current)
+	createEvent(t, client, "after-start")
+	waitForEvents(t, current, 2)
+
+	// A reload starts new informers and handlers, which must not log the events again
+	reloaded := newEventInformerSet(c, client)
+	if err := reloaded.start(ctx, cfg, 5*time.Second); err != nil {
+		t.Fatalf("Expected informers to sync, got %v", err)
+	}
+	tickers.stop()
+	current.stop()
+	current = reloaded
+	tickers = c.startResourceTickers(ctx, cfg, current)
+	waitForEvents(t, current, 2)
+
+	// Events the leader logged while this replica stood by are not logged on takeover
+	c.leading.Store(false)
+	createEvent(t, client, "while-standing-by")
+	waitForEvents(t, current, 3)
+	c.leading.Store(true)
+	createEvent(t, client, "after-takeover")
+	waitForEvents(t, current, 4)
+
+	tickers.stop()
+	current.stop()
+
+	expected := []string{"after-start", "after-takeover"}
+	names := loggedEventNames(logger)
+	if len(names) != len(expected) {
+		t.Fatalf("Expected events %v to be logged, got %v", expected, names)
+	}
+	for i, name := range expected {
+		if names[i] != name {
+			t.Errorf("Expected event '%s' at position %d, got '%s'", name, i, names[i])
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package collector
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// entryFilter drops the entries excluded by the configured filters before they are routed
+// to the sinks
+type entryFilter struct {
+	excludeNamespaces map[string]bool
+	selector          labels.Selector
+}
+
+// newEntryFilter creates an entryFilter from the configured filters
+func newEntryFilter(filters config.FilterConfig) (*entryFilter, error) {
+	selector, err := labels.Parse(filters.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector '%s': %w", filters.LabelSelector, err)
+	}
+
+	f := &entryFilter{
+		excludeNamespaces: make(map[string]bool, len(filters.ExcludeNamespaces)),
+		selector:          selector,
+	}
+	for _, namespace := range filters.ExcludeNamespaces {
+		f.excludeNamespaces[namespace] = true
+	}
+	return f, nil
+}
+
+// matches reports whether an entry passes the filters. Entries without labels, such as
+// containers, only match selectors that an empty label set satisfies.
+func (f *entryFilter) matches(entry any) bool {
+	metadata, ok := types.MetadataOf(entry)
+	if !ok {
+		return true
+	}
+	if f.excludeNamespaces[metadata.Namespace] {
+		return false
+	}
+	return f.selector.Matches(labels.Set(metadata.Labels))
+}
@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"testing"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+func TestEntryFilter_Matches(t *testing.T) {
+	entry := func(namespace string, labels map[string]string) types.ConfigMapData {
+		return types.ConfigMapData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "configmap", Name: "a", Namespace: namespace, Labels: labels}}
+	}
+
+	tests := []struct {
+		name     string
+		filters  config.FilterConfig
+		entry    any
+		expected bool
+	}{
+		{name: "no filters", entry: entry("default", nil), expected: true},
+		{name: "excluded namespace", filters: config.FilterConfig{ExcludeNamespaces: []string{"kube-system"}}, entry: entry("kube-system", nil), expected: false},
+		{name: "other namespace", filters: config.FilterConfig{ExcludeNamespaces: []string{"kube-system"}}, entry: entry("default", nil), expected: true},
+		{name: "matching selector", filters: config.FilterConfig{LabelSelector: "app=web"}, entry: entry("default", map[string]string{"app": "web"}), expected: true},
+		{name: "non-matching selector", filters: config.FilterConfig{LabelSelector: "app=web"}, entry: entry("default", map[string]string{"app": "db"}), expected: false},
+		{name: "negated selector without labels", filters: config.FilterConfig{LabelSelector: "app!=web"}, entry: types.ContainerData{}, expected: true},
+		{name: "entry without metadata", filters: config.FilterConfig{LabelSelector: "app=web"}, entry: "raw", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newEntryFilter(tt.filters)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if matches := filter.matches(tt.entry); matches != tt.expected {
+				t.Errorf("Expected matches to be %v, got %v", tt.expected, matches)
+			}
+		})
+	}
+
+	if _, err := newEntryFilter(config.FilterConfig{LabelSelector: "app in (a"}); err == nil {
+		t.Error("Expected an error for an invalid label selector")
+	}
+}
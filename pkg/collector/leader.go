@@ -0,0 +1,76 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/metrics"
+)
+
+// newLeaderElector creates an elector that contends for the configured Lease and marks the
+// collector as leading while it holds it
+func (c *Collector) newLeaderElector(client kubernetes.Interface, cfg config.LeaderElectionConfig) (*leaderelection.LeaderElector, error) {
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Name: cfg.LeaseName, Namespace: cfg.Namespace},
+		Client:     client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: cfg.Identity},
+	}
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: cfg.LeaseDuration,
+		RenewDeadline: cfg.RenewDeadline,
+		RetryPeriod:   cfg.RetryPeriod,
+		// Give up the lease on shutdown so a standby replica takes over without waiting for it to expire
+		ReleaseOnCancel: true,
+		Name:            cfg.LeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				klog.Infof("Acquired lease %s/%s as %s, logging entries", cfg.Namespace, cfg.LeaseName, cfg.Identity)
+				c.setLeading(true)
+			},
+			OnStoppedLeading: func() {
+				// Also called when the lease was never acquired
+				if c.setLeading(false) {
+					klog.Infof("Lost lease %s/%s, standing by", cfg.Namespace, cfg.LeaseName)
+				}
+			},
+			OnNewLeader: func(identity string) {
+				if identity != cfg.Identity {
+					klog.Infof("Standing by while %s holds lease %s/%s", identity, cfg.Namespace, cfg.LeaseName)
+				}
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid leader election configuration: %w", err)
+	}
+	return elector, nil
+}
+
+// setLeading marks whether this replica logs entries and exports it as the leader metric,
+// returning whether it was leading before
+func (c *Collector) setLeading(leading bool) bool {
+	value := 0.0
+	if leading {
+		value = 1
+	}
+	metrics.Leader.Set(value)
+	return c.leading.Swap(leading)
+}
+
+// runLeaderElection contends for the lease until ctx is done. Informers run on every replica
+// so that standby replicas have warm caches, but only the leader logs entries. A replica that
+// loses the lease contends for it again.
+func (c *Collector) runLeaderElection(ctx context.Context) {
+	for ctx.Err() == nil {
+		c.elector.Run(ctx)
+	}
+}
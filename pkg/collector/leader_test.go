@@ -0,0 +1,116 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+)
+
+type recordingLogger struct {
+	entries []any
+}
+
+func (l *recordingLogger) Log(entry any) error {
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func TestGatedLogger(t *testing.T) {
+	next := &recordingLogger{}
+	c := &Collector{}
+	logger := &gatedLogger{next: next, leading: &c.leading}
+
+	tests := []struct {
+		enabled bool
+		leading bool
+		logged  bool
+	}{
+		{enabled: false, leading: false, logged: false},
+		{enabled: true, leading: false, logged: false},
+		{enabled: false, leading: true, logged: false},
+		{enabled: true, leading: true, logged: true},
+	}
+	for _, tt := range tests {
+		next.entries = nil
+		logger.enabled.Store(tt.enabled)
+		c.leading.Store(tt.leading)
+
+		if err := logger.Log("entry"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if logged := len(next.entries) == 1; logged != tt.logged {
+			t.Errorf("Expected logged=%v with enabled=%v and leading=%v, got %v", tt.logged, tt.enabled, tt.leading, logged)
+		}
+	}
+}
+
+func TestLeaderElection(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	cfg := config.LeaderElectionConfig{
+		Enabled:       true,
+		LeaseName:     "kube-state-logs",
+		Namespace:     "monitoring",
+		Identity:      "kube-state-logs-0",
+		LeaseDuration: time.Second,
+		RenewDeadline: 500 * time.Millisecond,
+		RetryPeriod:   100 * time.Millisecond,
+	}
+
+	c := &Collector{}
+	var err error
+	c.elector, err = c.newLeaderElector(client, cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.runLeaderElection(ctx)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !c.leading.Load() {
+		if time.Now().After(deadline) {
+			cancel()
+			t.Fatal("Expected the collector to acquire the lease")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	lease, err := client.CoordinationV1().Leases("monitoring").Get(context.Background(), "kube-state-logs", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the lease to exist, got %v", err)
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != "kube-state-logs-0" {
+		t.Errorf("Expected holder 'kube-state-logs-0', got %v", lease.Spec.HolderIdentity)
+	}
+
+	cancel()
+	<-done
+	if c.leading.Load() {
+		t.Error("Expected the collector to stop leading after cancellation")
+	}
+}
+
+func TestLeaderElection_InvalidConfig(t *testing.T) {
+	c := &Collector{}
+	_, err := c.newLeaderElector(fake.NewSimpleClientset(), config.LeaderElectionConfig{
+		Enabled:       true,
+		LeaseName:     "kube-state-logs",
+		Namespace:     "monitoring",
+		Identity:      "kube-state-logs-0",
+		LeaseDuration: time.Second,
+		RenewDeadline: 2 * time.Second,
+		RetryPeriod:   100 * time.Millisecond,
+	})
+	if err == nil {
+		t.Error("Expected an error for a renew deadline longer than the lease duration")
+	}
+}
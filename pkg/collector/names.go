@@ -0,0 +1,226 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"go.goms.io/aks/kube-state-logs/pkg/collector/resources"
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/interfaces"
+)
+
+// builtinResource is a built-in resource type: its handler, the API resource the handler
+// reads and its kubectl short names
+type builtinResource struct {
+	newHandler func(kubernetes.Interface) interfaces.ResourceHandler
+	schema.GroupResource
+	shortNames []string
+}
+
+// builtinResources registers the built-in resource types. The plural, singular, kind and
+// short names of their API resources are accepted as aliases of their names.
+var builtinResources = map[string]builtinResource{
+	"pod":                              {handlerFactory(resources.NewPodHandler), schema.GroupResource{Resource: "pods"}, []string{"po"}},
+	"container":                        {handlerFactory(resources.NewContainerHandler), schema.GroupResource{Resource: "containers"}, nil},
+	"service":                          {handlerFactory(resources.NewServiceHandler), schema.GroupResource{Resource: "services"}, []string{"svc"}},
+	"node":                             {handlerFactory(resources.NewNodeHandler), schema.GroupResource{Resource: "nodes"}, []string{"no"}},
+	"deployment":                       {handlerFactory(resources.NewDeploymentHandler), schema.GroupResource{Group: "apps", Resource: "deployments"}, []string{"deploy"}},
+	"job":                              {handlerFactory(resources.NewJobHandler), schema.GroupResource{Group: "batch", Resource: "jobs"}, nil},
+	"cronjob":                          {handlerFactory(resources.NewCronJobHandler), schema.GroupResource{Group: "batch", Resource: "cronjobs"}, []string{"cj"}},
+	"configmap":                        {handlerFactory(resources.NewConfigMapHandler), schema.GroupResource{Resource: "configmaps"}, []string{"cm"}},
+	"secret":                           {handlerFactory(resources.NewSecretHandler), schema.GroupResource{Resource: "secrets"}, nil},
+	"persistentvolumeclaim":            {handlerFactory(resources.NewPersistentVolumeClaimHandler), schema.GroupResource{Resource: "persistentvolumeclaims"}, []string{"pvc"}},
+	"ingress":                          {handlerFactory(resources.NewIngressHandler), schema.GroupResource{Group: "networking.k8s.io", Resource: "ingresses"}, []string{"ing"}},
+	"horizontalpodautoscaler":          {handlerFactory(resources.NewHorizontalPodAutoscalerHandler), schema.GroupResource{Group: "autoscaling", Resource: "horizontalpodautoscalers"}, []string{"hpa"}},
+	"serviceaccount":                   {handlerFactory(resources.NewServiceAccountHandler), schema.GroupResource{Resource: "serviceaccounts"}, []string{"sa"}},
+	"endpoints":                        {handlerFactory(resources.NewEndpointsHandler), schema.GroupResource{Resource: "endpoints"}, []string{"ep"}},
+	"endpointslice":                    {handlerFactory(resources.NewEndpointSliceHandler), schema.GroupResource{Group: "discovery.k8s.io", Resource: "endpointslices"}, nil},
+	"persistentvolume":                 {handlerFactory(resources.NewPersistentVolumeHandler), schema.GroupResource{Resource: "persistentvolumes"}, []string{"pv"}},
+	"resourcequota":                    {handlerFactory(resources.NewResourceQuotaHandler), schema.GroupResource{Resource: "resourcequotas"}, []string{"quota"}},
+	"poddisruptionbudget":              {handlerFactory(resources.NewPodDisruptionBudgetHandler), schema.GroupResource{Group: "policy", Resource: "poddisruptionbudgets"}, []string{"pdb"}},
+	"storageclass":                     {handlerFactory(resources.NewStorageClassHandler), schema.GroupResource{Group: "storage.k8s.io", Resource: "storageclasses"}, []string{"sc"}},
+	"networkpolicy":                    {handlerFactory(resources.NewNetworkPolicyHandler), schema.GroupResource{Group: "networking.k8s.io", Resource: "networkpolicies"}, []string{"netpol"}},
+	"replicationcontroller":            {handlerFactory(resources.NewReplicationControllerHandler), schema.GroupResource{Resource: "replicationcontrollers"}, []string{"rc"}},
+	"limitrange":                       {handlerFactory(resources.NewLimitRangeHandler), schema.GroupResource{Resource: "limitranges"}, []string{"limits"}},
+	"lease":                            {handlerFactory(resources.NewLeaseHandler), schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}, nil},
+	"role":                             {handlerFactory(resources.NewRoleHandler), schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "roles"}, nil},
+	"clusterrole":                      {handlerFactory(resources.NewClusterRoleHandler), schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}, nil},
+	"rolebinding":                      {handlerFactory(resources.NewRoleBindingHandler), schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "rolebindings"}, nil},
+	"clusterrolebinding":               {handlerFactory(resources.NewClusterRoleBindingHandler), schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"}, nil},
+	"volumeattachment":                 {handlerFactory(resources.NewVolumeAttachmentHandler), schema.GroupResource{Group: "storage.k8s.io", Resource: "volumeattachments"}, nil},
+	"certificatesigningrequest":        {handlerFactory(resources.NewCertificateSigningRequestHandler), schema.GroupResource{Group: "certificates.k8s.io", Resource: "certificatesigningrequests"}, []string{"csr"}},
+	"namespace":                        {handlerFactory(resources.NewNamespaceHandler), schema.GroupResource{Resource: "namespaces"}, []string{"ns"}},
+	"daemonset":                        {handlerFactory(resources.NewDaemonSetHandler), schema.GroupResource{Group: "apps", Resource: "daemonsets"}, []string{"ds"}},
+	"statefulset":                      {handlerFactory(resources.NewStatefulSetHandler), schema.GroupResource{Group: "apps", Resource: "statefulsets"}, []string{"sts"}},
+	"replicaset":                       {handlerFactory(resources.NewReplicaSetHandler), schema.GroupResource{Group: "apps", Resource: "replicasets"}, []string{"rs"}},
+	"mutatingwebhookconfiguration":     {handlerFactory(resources.NewMutatingWebhookConfigurationHandler), schema.GroupResource{Group: "admissionregistration.k8s.io", Resource: "mutatingwebhookconfigurations"}, nil},
+	"validatingwebhookconfiguration":   {handlerFactory(resources.NewValidatingWebhookConfigurationHandler), schema.GroupResource{Group: "admissionregistration.k8s.io", Resource: "validatingwebhookconfigurations"}, nil},
+	"ingressclass":                     {handlerFactory(resources.NewIngressClassHandler), schema.GroupResource{Group: "networking.k8s.io", Resource: "ingressclasses"}, nil},
+	"priorityclass":                    {handlerFactory(resources.NewPriorityClassHandler), schema.GroupResource{Group: "scheduling.k8s.io", Resource: "priorityclasses"}, []string{"pc"}},
+	"runtimeclass":                     {handlerFactory(resources.NewRuntimeClassHandler), schema.GroupResource{Group: "node.k8s.io", Resource: "runtimeclasses"}, nil},
+	"validatingadmissionpolicy":        {handlerFactory(resources.NewValidatingAdmissionPolicyHandler), schema.GroupResource{Group: "admissionregistration.k8s.io", Resource: "validatingadmissionpolicies"}, nil},
+	"validatingadmissionpolicybinding": {handlerFactory(resources.NewValidatingAdmissionPolicyBindingHandler), schema.GroupResource{Group: "admissionregistration.k8s.io", Resource: "validatingadmissionpolicybindings"}, nil},
+	"event":                            {handlerFactory(resources.NewEventHandler), schema.GroupResource{Group: "events.k8s.io", Resource: "events"}, []string{"ev"}},
+}
+
+// nameResolver maps the names a resource type can be referred to by (its name, plural,
+// singular, kind and short names, case-insensitive) to the name of its handler
+type nameResolver struct {
+	aliases map[string]string
+	names   []string // Names of the built-in resource types and configured CRDs
+}
+
+// newNameResolver creates a nameResolver for the built-in resource types and the configured
+// CRDs. Names from the built-in table are always known; the names of CRDs and any names the
+// table lacks are looked up with discovery when it is available.
+func newNameResolver(discoveryClient discovery.DiscoveryInterface, crds []config.CRDConfig) *nameResolver {
+	r := &nameResolver{aliases: make(map[string]string)}
+
+	resources := make(map[schema.GroupResource]string)
+	for _, name := range slices.Sorted(maps.Keys(builtinResources)) {
+		resource := builtinResources[name]
+		resources[resource.GroupResource] = name
+		r.names = append(r.names, name)
+		r.add(name, name)
+		r.add(name, resource.Resource)
+		r.add(name, resource.GroupResource.String())
+		for _, shortName := range resource.shortNames {
+			r.add(name, shortName)
+		}
+	}
+
+	for _, crdConfig := range crds {
+		gvr, err := crdConfig.GroupVersionResource()
+		if err != nil {
+			continue
+		}
+		name := crdConfig.Name()
+		resources[gvr.GroupResource()] = name
+		r.names = append(r.names, name)
+		r.add(name, name)
+		r.add(name, gvr.Resource)
+	}
+
+	if discoveryClient == nil {
+		return r
+	}
+
+	// Partial results are returned when some API groups are unavailable
+	_, lists, err := discoveryClient.ServerGroupsAndResources()
+	if err != nil {
+		klog.Warningf("Resource discovery failed, resource names are resolved with the built-in table: %v", err)
+	}
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, apiResource := range list.APIResources {
+			name, exists := resources[gv.WithResource(apiResource.Name).GroupResource()]
+			if !exists {
+				continue
+			}
+			r.add(name, apiResource.SingularName)
+			r.add(name, apiResource.Kind)
+			for _, shortName := range apiResource.ShortNames {
+				r.add(name, shortName)
+			}
+		}
+	}
+
+	return r
+}
+
+// add registers an alias, unless it already refers to another resource type. Built-in
+// resource types are added first, so they win over CRDs with the same names.
+func (r *nameResolver) add(name, alias string) {
+	alias = strings.ToLower(alias)
+	if alias == "" {
+		return
+	}
+	if existing, exists := r.aliases[alias]; exists {
+		if existing != name {
+			klog.V(2).Infof("Resource name %s refers to %s, not %s", alias, existing, name)
+		}
+		return
+	}
+	r.aliases[alias] = name
+}
+
+// resolve returns the resource type a name refers to
+func (r *nameResolver) resolve(name string) (string, bool) {
+	resolved, exists := r.aliases[strings.ToLower(strings.TrimSpace(name))]
+	return resolved, exists
+}
+
+// resolveConfig returns a copy of a configuration with every resource name replaced by the
+// name of its resource type, returning an error per unknown name. Names in sink routes that
+// are not resource types, such as init_container, are kept as they are.
+func resolveConfig(cfg *config.Config, resolver *nameResolver) (*config.Config, error) {
+	var errs []error
+	resolveAll := func(names []string, field string) []string {
+		var resolved []string
+		for _, name := range names {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			resourceType, exists := resolver.resolve(name)
+			if !exists {
+				errs = append(errs, fmt.Errorf("unknown resource type '%s' in %s", name, field))
+				continue
+			}
+			if !slices.Contains(resolved, resourceType) {
+				resolved = append(resolved, resourceType)
+			}
+		}
+		return resolved
+	}
+
+	resolved := *cfg
+	resolved.Resources = resolveAll(cfg.Resources, "resources")
+	resolved.EventResources = resolveAll(cfg.EventResources, "event-driven resources")
+	resolved.ChangeOnly = resolveAll(cfg.ChangeOnly, "change-only resources")
+
+	resolved.ResourceConfigs = nil
+	for _, resourceConfig := range cfg.ResourceConfigs {
+		if name, exists := resolver.resolve(resourceConfig.Name); exists {
+			resourceConfig.Name = name
+		} else {
+			errs = append(errs, fmt.Errorf("unknown resource type '%s' in resource configs", resourceConfig.Name))
+		}
+		resolved.ResourceConfigs = append(resolved.ResourceConfigs, resourceConfig)
+	}
+
+	// CRDs are collected because they are configured, so they are dropped from the resources
+	resolved.Resources = slices.DeleteFunc(resolved.Resources, func(name string) bool {
+		_, exists := builtinResources[name]
+		return !exists
+	})
+
+	resolved.Sinks = nil
+	for _, sinkConfig := range cfg.Sinks {
+		var routes []string
+		for _, name := range sinkConfig.Resources {
+			if resourceType, exists := resolver.resolve(name); exists {
+				name = resourceType
+			}
+			routes = append(routes, name)
+		}
+		sinkConfig.Resources = routes
+		resolved.Sinks = append(resolved.Sinks, sinkConfig)
+	}
+
+	if len(errs) > 0 {
+		errs = append(errs, fmt.Errorf("valid resource types are %s; plural, short and kind names are accepted as well", strings.Join(resolver.names, ", ")))
+		return nil, errors.Join(errs...)
+	}
+	return &resolved, nil
+}
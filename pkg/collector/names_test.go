@@ -0,0 +1,164 @@
+package collector
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+)
+
+var testCRDs = []config.CRDConfig{{APIVersion: "cert-manager.io/v1", Resource: "certificates"}}
+
+func testNameResolver() *nameResolver {
+	discovery := fake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	discovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{{Name: "deployments", SingularName: "deployment", Kind: "Deployment", ShortNames: []string{"deploy"}}},
+		},
+		{
+			GroupVersion: "cert-manager.io/v1",
+			APIResources: []metav1.APIResource{{Name: "certificates", SingularName: "certificate", Kind: "Certificate", ShortNames: []string{"cert", "certs"}}},
+		},
+	}
+	return newNameResolver(discovery, testCRDs)
+}
+
+func TestNameResolver_Resolve(t *testing.T) {
+	resolver := testNameResolver()
+
+	tests := map[string]string{
+		"pod":                          "pod",
+		"pods":                         "pod",
+		"po":                           "pod",
+		"Pod":                          "pod",
+		"deployments.apps":             "deployment",
+		"deploy":                       "deployment",
+		"HorizontalPodAutoscaler":      "horizontalpodautoscaler",
+		"hpa":                          "horizontalpodautoscaler",
+		"endpoints":                    "endpoints",
+		"ep":                           "endpoints",
+		"certificatesigningrequests":   "certificatesigningrequest",
+		"certificates.cert-manager.io": "certificates.cert-manager.io",
+		"certificates":                 "certificates.cert-manager.io",
+		"certificate":                  "certificates.cert-manager.io",
+		"Certificate":                  "certificates.cert-manager.io",
+		"cert":                         "certificates.cert-manager.io",
+	}
+	for name, expected := range tests {
+		if resolved, ok := resolver.resolve(name); !ok || resolved != expected {
+			t.Errorf("Expected '%s' to resolve to '%s', got '%s'", name, expected, resolved)
+		}
+	}
+
+	if resolved, ok := resolver.resolve("widgets"); ok {
+		t.Errorf("Expected 'widgets' to be unknown, got '%s'", resolved)
+	}
+}
+
+func TestNameResolver_WithoutDiscovery(t *testing.T) {
+	resolver := newNameResolver(nil, testCRDs)
+
+	for name, expected := range map[string]string{"deployments": "deployment", "sts": "statefulset", "certificates": "certificates.cert-manager.io"} {
+		if resolved, ok := resolver.resolve(name); !ok || resolved != expected {
+			t.Errorf("Expected '%s' to resolve to '%s', got '%s'", name, expected, resolved)
+		}
+	}
+	if _, ok := resolver.resolve("cert"); ok {
+		t.Errorf("Expected CRD short names to need discovery")
+	}
+}
+
+func TestResolveConfig(t *testing.T) {
+	cfg := &config.Config{
+		LogInterval:     time.Minute,
+		Resources:       []string{"pods", "po", "deployments", "certificates"},
+		ResourceConfigs: []config.ResourceConfig{{Name: "pods", Interval: 30 * time.Second}, {Name: "cert", Interval: 5 * time.Minute}},
+		CRDs:            testCRDs,
+		EventResources:  []string{"Pod"},
+		ChangeOnly:      []string{"cm"},
+		Sinks:           []config.SinkConfig{{Name: "containers", Type: "stdout", Resources: []string{"containers", "init_container"}}},
+	}
+
+	resolved, err := resolveConfig(cfg, testNameResolver())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := &config.Config{
+		LogInterval:     time.Minute,
+		Resources:       []string{"pod", "deployment"},
+		ResourceConfigs: []config.ResourceConfig{{Name: "pod", Interval: 30 * time.Second}, {Name: "certificates.cert-manager.io", Interval: 5 * time.Minute}},
+		CRDs:            testCRDs,
+		EventResources:  []string{"pod"},
+		ChangeOnly:      []string{"configmap"},
+		Sinks:           []config.SinkConfig{{Name: "containers", Type: "stdout", Resources: []string{"container", "init_container"}}},
+	}
+	if !reflect.DeepEqual(resolved, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, resolved)
+	}
+	if cfg.Resources[0] != "pods" {
+		t.Errorf("Expected the original configuration to be unchanged, got %v", cfg.Resources)
+	}
+}
+
+func TestResolveConfig_UnknownNames(t *testing.T) {
+	cfg := &config.Config{
+		Resources:       []string{"pod", "widgets"},
+		ResourceConfigs: []config.ResourceConfig{{Name: "gadgets", Interval: time.Minute}},
+		EventResources:  []string{"sprockets"},
+	}
+
+	_, err := resolveConfig(cfg, testNameResolver())
+	if err == nil {
+		t.Fatal("Expected an error for unknown resource types")
+	}
+	for _, message := range []string{
+		"unknown resource type 'widgets' in resources",
+		"unknown resource type 'gadgets' in resource configs",
+		"unknown resource type 'sprockets' in event-driven resources",
+		"valid resource types are certificatesigningrequest, clusterrole,",
+		"certificates.cert-manager.io",
+	} {
+		if !strings.Contains(err.Error(), message) {
+			t.Errorf("Expected error to contain '%s', got '%v'", message, err)
+		}
+	}
+}
+
+func TestChartResourceAliases(t *testing.T) {
+	data, err := os.ReadFile("../../charts/kube-state-logs/templates/_helpers.tpl")
+	if err != nil {
+		t.Fatalf("Expected to read the chart helpers, got %v", err)
+	}
+	_, block, _ := strings.Cut(string(data), `{{- define "kube-state-logs.resourceAliases" -}}`)
+	block, _, _ = strings.Cut(block, "{{- end }}")
+
+	var chartAliases map[string][]string
+	if err := yaml.Unmarshal([]byte(block), &chartAliases); err != nil {
+		t.Fatalf("Expected the chart aliases to be YAML, got %v", err)
+	}
+
+	expected := make(map[string][]string)
+	for name, resource := range builtinResources {
+		var aliases []string
+		if resource.Resource != name {
+			aliases = append(aliases, resource.Resource)
+		}
+		if resource.Group != "" {
+			aliases = append(aliases, resource.GroupResource.String())
+		}
+		expected[name] = append(aliases, resource.shortNames...)
+	}
+	if !reflect.DeepEqual(chartAliases, expected) {
+		t.Errorf("Expected the chart aliases to match the built-in resource types %v, got %v", expected, chartAliases)
+	}
+}
@@ -0,0 +1,216 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/interfaces"
+	"go.goms.io/aks/kube-state-logs/pkg/metrics"
+	"go.goms.io/aks/kube-state-logs/pkg/sinks"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// reloadSyncTimeout bounds how long a reload waits for new informers to sync, e.g. when
+// the service account is not allowed to list a newly configured resource
+const reloadSyncTimeout = 2 * time.Minute
+
+// Reload applies a new configuration to the collector. Sinks are recreated if they changed.
+// If the resources, namespaces, CRDs or event-driven resources changed, a new set of
+// informers is started and takes over from the current one once it is synced, so informer
+// events are neither lost nor logged twice. Tickers are restarted with the new intervals.
+// If the configuration cannot be applied, the current one stays in effect.
+func (c *Collector) Reload(cfg *config.Config) error {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	// Discovery runs again so that names of newly installed CRDs are known
+	cfg, err := resolveConfig(cfg, newNameResolver(c.client.Discovery(), cfg.CRDs))
+	if err != nil {
+		return err
+	}
+	filter, err := newEntryFilter(cfg.Filters)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	if reflect.DeepEqual(cfg, c.config) {
+		c.mu.Unlock()
+		klog.Info("Configuration unchanged")
+		return nil
+	}
+
+	// Before Run, only the sinks exist yet
+	if c.ctx == nil {
+		defer c.mu.Unlock()
+		if !reflect.DeepEqual(cfg.Sinks, c.config.Sinks) {
+			if err := c.logger.replace(cfg.Sinks); err != nil {
+				return err
+			}
+		}
+		c.logger.filter.Store(filter)
+		c.config = cfg
+		return nil
+	}
+	if c.ctx.Err() != nil {
+		c.mu.Unlock()
+		return errors.New("collector is stopped")
+	}
+	current, ctx := c.config, c.ctx
+	c.mu.Unlock()
+
+	// New informers sync without holding mu, so the current ones keep logging meanwhile
+	var informers *informerSet
+	if informersChanged(current, cfg) {
+		klog.Info("Starting informers for the new configuration...")
+		informers = c.newInformerSet(cfg)
+		if err := informers.start(ctx, cfg, reloadSyncTimeout); err != nil {
+			informers.stop()
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Run may have returned while the informers were syncing
+	if ctx.Err() != nil {
+		if informers != nil {
+			informers.stop()
+		}
+		return errors.New("collector is stopped")
+	}
+
+	c.tickers.stop()
+
+	if !reflect.DeepEqual(cfg.Sinks, current.Sinks) {
+		if err := c.logger.replace(cfg.Sinks); err != nil {
+			if informers != nil {
+				informers.stop()
+			}
+			c.tickers = c.startResourceTickers(ctx, current, c.informers)
+			return err
+		}
+		klog.Infof("Replaced sinks with %d configured sinks", len(cfg.Sinks))
+	}
+
+	if informers != nil {
+		previous := c.informers
+		previous.logger.enabled.Store(false)
+		informers.logger.enabled.Store(true)
+		c.informers = informers
+		previous.stop()
+	}
+
+	c.logger.filter.Store(filter)
+	c.config = cfg
+	c.tickers = c.startResourceTickers(ctx, cfg, c.informers)
+	return nil
+}
+
+// informersChanged reports whether two configurations need different informers
+func informersChanged(current, updated *config.Config) bool {
+	return !reflect.DeepEqual(current.Resources, updated.Resources) ||
+		!reflect.DeepEqual(current.Namespaces, updated.Namespaces) ||
+		!reflect.DeepEqual(current.CRDs, updated.CRDs) ||
+		!reflect.DeepEqual(current.EventResources, updated.EventResources)
+}
+
+// switchingLogger routes entries to the configured sinks, which can be replaced at runtime
+type switchingLogger struct {
+	mu      sync.RWMutex
+	current *sinks.Router
+	configs []config.SinkConfig
+	filter  atomic.Pointer[entryFilter] // nil logs every entry
+}
+
+// newSwitchingLogger creates a switchingLogger with the given sinks
+func newSwitchingLogger(sinkConfigs []config.SinkConfig) (*switchingLogger, error) {
+	router, err := sinks.NewFromConfig(sinkConfigs)
+	if err != nil {
+		return nil, err
+	}
+	return &switchingLogger{current: router, configs: sinkConfigs}, nil
+}
+
+// Log routes an entry to the current sinks
+func (l *switchingLogger) Log(entry any) error {
+	return l.LogContext(context.Background(), entry)
+}
+
+// LogContext routes an entry that passes the filters to the current sinks, giving up on sinks that wait for room
+// once ctx is done
+func (l *switchingLogger) LogContext(ctx context.Context, entry any) error {
+	if filter := l.filter.Load(); filter != nil && !filter.matches(entry) {
+		return nil
+	}
+
+	resourceType := "unknown"
+	if metadata, ok := types.MetadataOf(entry); ok {
+		resourceType = metadata.ResourceType
+	}
+	metrics.EntriesEmitted.WithLabelValues(resourceType).Inc()
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.current.LogContext(ctx, entry)
+}
+
+// replace closes the current sinks and creates the given ones. The current sinks are closed
+// first so that files and spool directories are never used by two sinks at once. If the new
+// sinks cannot be created, the previous ones are created again.
+func (l *switchingLogger) replace(sinkConfigs []config.SinkConfig) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.current.Close(); err != nil {
+		klog.Errorf("Failed to close sinks: %v", err)
+	}
+
+	router, err := sinks.NewFromConfig(sinkConfigs)
+	if err != nil {
+		err = fmt.Errorf("failed to create sinks: %w", err)
+		previous, restoreErr := sinks.NewFromConfig(l.configs)
+		if restoreErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to restore previous sinks: %w", restoreErr))
+		}
+		l.current = previous
+		return err
+	}
+
+	l.current = router
+	l.configs = sinkConfigs
+	return nil
+}
+
+// Close closes the current sinks
+func (l *switchingLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.current.Close()
+}
+
+// gatedLogger forwards the informer events of an informer set only while that set is in
+// use, so that a set being synced or replaced during a reload does not log them twice, and
+// only while the replica is leading
+type gatedLogger struct {
+	next    interfaces.Logger
+	enabled atomic.Bool
+	leading *atomic.Bool
+}
+
+// Log forwards an entry if the logger is enabled and the replica is leading
+func (l *gatedLogger) Log(entry any) error {
+	if !l.enabled.Load() || !l.leading.Load() {
+		return nil
+	}
+	return l.next.Log(entry)
+}
@@ -30,6 +30,7 @@ func (h *CertificateSigningRequestHandler) SetupInformer(factory informers.Share
 	// Create certificatesigningrequest informer
 	informer := factory.Certificates().V1().CertificateSigningRequests().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
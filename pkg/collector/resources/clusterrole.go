@@ -30,6 +30,7 @@ func (h *ClusterRoleHandler) SetupInformer(factory informers.SharedInformerFacto
 	// Create clusterrole informer
 	informer := factory.Rbac().V1().ClusterRoles().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
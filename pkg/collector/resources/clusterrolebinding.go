@@ -30,6 +30,7 @@ func (h *ClusterRoleBindingHandler) SetupInformer(factory informers.SharedInform
 	// Create clusterrolebinding informer
 	informer := factory.Rbac().V1().ClusterRoleBindings().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
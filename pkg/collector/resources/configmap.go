@@ -30,6 +30,7 @@ func (h *ConfigMapHandler) SetupInformer(factory informers.SharedInformerFactory
 	// Create configmap informer
 	informer := factory.Core().V1().ConfigMaps().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -42,9 +42,29 @@ func (h *ContainerHandler) SetupInformer(factory informers.SharedInformerFactory
 	// Create pod informer (containers are accessed through pods)
 	informer := factory.Core().V1().Pods().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(h.createPodEntries)
 	return nil
 }
 
+// createPodEntries creates entries for every container and init container of a pod
+func (h *ContainerHandler) createPodEntries(obj any) []any {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil
+	}
+
+	var entries []any
+	for i := range pod.Status.ContainerStatuses {
+		entry := h.createLogEntry(pod, &pod.Status.ContainerStatuses[i], false)
+		entries = append(entries, &entry)
+	}
+	for i := range pod.Status.InitContainerStatuses {
+		entry := h.createLogEntry(pod, &pod.Status.InitContainerStatuses[i], true)
+		entries = append(entries, &entry)
+	}
+	return entries
+}
+
 // Collect gathers container metrics from the cluster (uses cache)
 func (h *ContainerHandler) Collect(ctx context.Context, namespaces []string) ([]any, error) {
 	// Get all pods from the cache
@@ -774,3 +774,62 @@ func TestContainerHandler_TerminatedContainerTimeFiltering(t *testing.T) {
 		t.Errorf("Expected pod name 'test-pod-recent', got '%s'", entry.PodName)
 	}
 }
+
+func TestContainerHandler_DeletionLogging(t *testing.T) {
+	containers := []corev1.Container{
+		*createTestContainer("app", "nginx:latest", true),
+		*createTestContainer("sidecar", "busybox:latest", true),
+	}
+	pod := createTestPodWithContainers("test-pod", "default", containers)
+
+	client := fake.NewSimpleClientset(pod)
+	handler := NewContainerHandler(client)
+	factory := informers.NewSharedInformerFactory(client, 0)
+	logger := &testutils.MockLogger{}
+
+	if err := handler.SetupInformer(factory, logger, 0); err != nil {
+		t.Fatalf("Failed to setup informer: %v", err)
+	}
+	if err := handler.EnableDeletionLogging([]string{}); err != nil {
+		t.Fatalf("Failed to enable deletion logging: %v", err)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	if len(logger.GetLogs()) != 0 {
+		t.Fatalf("Expected no entries before the pod is deleted, got %d", len(logger.GetLogs()))
+	}
+
+	if err := client.CoreV1().Pods("default").Delete(context.Background(), pod.Name, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete pod: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(logger.GetLogs()) < len(containers) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	logs := logger.GetLogs()
+	if len(logs) != len(containers) {
+		t.Fatalf("Expected %d tombstone entries, got %d", len(containers), len(logs))
+	}
+
+	for _, log := range logs {
+		entry, ok := log.(*types.ContainerData)
+		if !ok {
+			t.Fatalf("Expected *ContainerData type, got %T", log)
+		}
+		if !entry.Deleted || entry.DeletedTimestamp == nil {
+			t.Errorf("Expected container %s to be marked as deleted", entry.Name)
+		}
+		if entry.EventType != utils.EventTypeDelete {
+			t.Errorf("Expected event type '%s', got '%s'", utils.EventTypeDelete, entry.EventType)
+		}
+		if entry.PodName != "test-pod" || entry.State != ContainerStateRunning {
+			t.Errorf("Expected last known state of test-pod, got pod %s in state %s", entry.PodName, entry.State)
+		}
+	}
+}
@@ -21,7 +21,7 @@ type CRDHandler struct {
 	informer     cache.SharedIndexInformer
 	logger       interfaces.Logger
 	gvr          schema.GroupVersionResource
-	resourceName string
+	resourceName string   // <resource>.<group>, used as the resource type of entries
 	customFields []string // JSONPath-like field paths to extract
 }
 
@@ -45,6 +45,21 @@ func (h *CRDHandler) SetupInformer(factory dynamicinformer.DynamicSharedInformer
 	return nil
 }
 
+// GetInformer returns the informer
+func (h *CRDHandler) GetInformer() cache.SharedIndexInformer {
+	return h.informer
+}
+
+// EnableEventLogging registers informer event handlers that log entries on create and update
+func (h *CRDHandler) EnableEventLogging(namespaces []string) error {
+	return utils.RegisterEventLogging(h.informer, h.logger, utils.NewEntryFunc(h.createLogEntry), namespaces)
+}
+
+// EnableDeletionLogging registers an informer event handler that logs a tombstone entry on delete
+func (h *CRDHandler) EnableDeletionLogging(namespaces []string) error {
+	return utils.RegisterDeletionLogging(h.informer, h.logger, utils.NewEntryFunc(h.createLogEntry), namespaces)
+}
+
 // Collect gathers CRD metrics from the cluster (uses cache)
 func (h *CRDHandler) Collect(ctx context.Context, namespaces []string) ([]any, error) {
 	var entries []any
@@ -97,7 +112,7 @@ func (h *CRDHandler) createLogEntry(obj *unstructured.Unstructured) types.CRDDat
 	// Create data structure
 	data := types.CRDData{
 		LogEntryMetadata: types.LogEntryMetadata{
-			ResourceType:     "crd",
+			ResourceType:     h.resourceName,
 			Name:             utils.ExtractName(obj),
 			Namespace:        utils.ExtractNamespace(obj),
 			CreatedTimestamp: utils.ExtractCreationTimestamp(obj),
@@ -190,8 +190,8 @@ func TestCRDHandler(t *testing.T) {
 				if !ok {
 					t.Fatalf("Expected CRDData type, got %T", entry)
 				}
-				if crdData.ResourceType != "crd" {
-					t.Errorf("Expected resource type 'crd', got %s", crdData.ResourceType)
+				if crdData.ResourceType != "myresource" {
+					t.Errorf("Expected resource type 'myresource', got %s", crdData.ResourceType)
 				}
 				if crdData.Name == "" {
 					t.Error("Entry name should not be empty")
@@ -30,6 +30,7 @@ func (h *CronJobHandler) SetupInformer(factory informers.SharedInformerFactory,
 	// Create cronjob informer
 	informer := factory.Batch().V1().CronJobs().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -30,6 +30,7 @@ func (h *DaemonSetHandler) SetupInformer(factory informers.SharedInformerFactory
 	// Create daemonset informer
 	informer := factory.Apps().V1().DaemonSets().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -31,6 +31,7 @@ func (h *DeploymentHandler) SetupInformer(factory informers.SharedInformerFactor
 	// Create deployment informer
 	informer := factory.Apps().V1().Deployments().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -443,3 +443,92 @@ func TestDeploymentHandler_Collect_NamespaceFiltering(t *testing.T) {
 		t.Error("Did not expect entry from kube-system namespace")
 	}
 }
+
+func TestDeploymentHandler_EventLogging(t *testing.T) {
+	existing := createTestDeployment("existing-deployment", "default", 3, appsv1.RollingUpdateDeploymentStrategyType)
+	client := fake.NewSimpleClientset(existing)
+	handler := NewDeploymentHandler(client)
+	factory := informers.NewSharedInformerFactory(client, 0)
+	logger := &testutils.MockLogger{}
+
+	if err := handler.SetupInformer(factory, logger, 0); err != nil {
+		t.Fatalf("Failed to setup informer: %v", err)
+	}
+	if err := handler.EnableEventLogging([]string{"default"}); err != nil {
+		t.Fatalf("Failed to enable event logging: %v", err)
+	}
+	if err := handler.EnableDeletionLogging([]string{"default"}); err != nil {
+		t.Fatalf("Failed to enable deletion logging: %v", err)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	// The existing deployment comes from the initial list and must not be logged as created
+	ctx := context.Background()
+	created := createTestDeployment("new-deployment", "default", 1, appsv1.RollingUpdateDeploymentStrategyType)
+	if _, err := client.AppsV1().Deployments("default").Create(ctx, created, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create deployment: %v", err)
+	}
+	ignored := createTestDeployment("ignored-deployment", "kube-system", 1, appsv1.RollingUpdateDeploymentStrategyType)
+	if _, err := client.AppsV1().Deployments("kube-system").Create(ctx, ignored, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create deployment: %v", err)
+	}
+
+	updated := existing.DeepCopy()
+	updated.Status.ReadyReplicas = 3
+	if _, err := client.AppsV1().Deployments("default").Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update deployment: %v", err)
+	}
+
+	// Deletes are logged by a separate informer handler, so wait for the update first
+	waitForLogs := func(count int) {
+		deadline := time.Now().Add(5 * time.Second)
+		for len(logger.GetLogs()) < count && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitForLogs(2)
+	if err := client.AppsV1().Deployments("default").Delete(ctx, existing.Name, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete deployment: %v", err)
+	}
+
+	expected := []string{utils.EventTypeCreate, utils.EventTypeUpdate, utils.EventTypeDelete}
+	waitForLogs(len(expected))
+
+	logs := logger.GetLogs()
+	if len(logs) != len(expected) {
+		t.Fatalf("Expected %d event entries, got %d", len(expected), len(logs))
+	}
+
+	expectedNames := []string{"new-deployment", "existing-deployment", "existing-deployment"}
+	for i, log := range logs {
+		entry, ok := log.(*types.DeploymentData)
+		if !ok {
+			t.Fatalf("Expected *DeploymentData type, got %T", log)
+		}
+		if entry.Name != expectedNames[i] {
+			t.Errorf("Expected name '%s', got '%s'", expectedNames[i], entry.Name)
+		}
+		if entry.EventType != expected[i] {
+			t.Errorf("Expected event type '%s', got '%s'", expected[i], entry.EventType)
+		}
+	}
+
+	if entry := logs[1].(*types.DeploymentData); entry.ReadyReplicas != 3 {
+		t.Errorf("Expected updated entry to have 3 ready replicas, got %d", entry.ReadyReplicas)
+	}
+
+	tombstone := logs[2].(*types.DeploymentData)
+	if !tombstone.Deleted || tombstone.DeletedTimestamp == nil {
+		t.Error("Expected delete entry to be marked as deleted with a deletion timestamp")
+	}
+	if tombstone.ReadyReplicas != 3 {
+		t.Errorf("Expected tombstone to carry the last known state, got %d ready replicas", tombstone.ReadyReplicas)
+	}
+	if logs[0].(*types.DeploymentData).Deleted {
+		t.Error("Expected create entry not to be marked as deleted")
+	}
+}
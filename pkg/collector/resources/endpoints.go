@@ -30,6 +30,7 @@ func (h *EndpointsHandler) SetupInformer(factory informers.SharedInformerFactory
 	// Create endpoints informer
 	informer := factory.Core().V1().Endpoints().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
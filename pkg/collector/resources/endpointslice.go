@@ -0,0 +1,152 @@
+package resources
+
+import (
+	"context"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+
+	"go.goms.io/aks/kube-state-logs/pkg/interfaces"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+	"go.goms.io/aks/kube-state-logs/pkg/utils"
+)
+
+// EndpointSliceHandler handles collection of endpointslice metrics
+type EndpointSliceHandler struct {
+	utils.BaseHandler
+}
+
+// NewEndpointSliceHandler creates a new EndpointSliceHandler
+func NewEndpointSliceHandler(client kubernetes.Interface) *EndpointSliceHandler {
+	return &EndpointSliceHandler{
+		BaseHandler: utils.NewBaseHandler(client),
+	}
+}
+
+// SetupInformer sets up the endpointslice informer
+func (h *EndpointSliceHandler) SetupInformer(factory informers.SharedInformerFactory, logger interfaces.Logger, resyncPeriod time.Duration) error {
+	// Create endpointslice informer
+	informer := factory.Discovery().V1().EndpointSlices().Informer()
+	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
+	return nil
+}
+
+// Collect gathers endpointslice metrics from the cluster (uses cache)
+func (h *EndpointSliceHandler) Collect(ctx context.Context, namespaces []string) ([]any, error) {
+	var entries []any
+
+	// Get all endpointslices from the cache
+	endpointSlices := utils.SafeGetStoreList(h.GetInformer())
+	listTime := time.Now()
+
+	for _, obj := range endpointSlices {
+		slice, ok := obj.(*discoveryv1.EndpointSlice)
+		if !ok {
+			continue
+		}
+
+		if !utils.ShouldIncludeNamespace(namespaces, slice.Namespace) {
+			continue
+		}
+
+		entry := h.createLogEntry(slice)
+		entry.Timestamp = listTime
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// createLogEntry creates an EndpointSliceData from an endpointslice
+func (h *EndpointSliceHandler) createLogEntry(slice *discoveryv1.EndpointSlice) types.EndpointSliceData {
+	endpoints := []types.EndpointSliceEndpointData{}
+	var readyCount, servingCount, terminatingCount int
+
+	for _, endpoint := range slice.Endpoints {
+		data := types.EndpointSliceEndpointData{
+			Addresses:   endpoint.Addresses,
+			Ready:       endpoint.Conditions.Ready,
+			Serving:     endpoint.Conditions.Serving,
+			Terminating: endpoint.Conditions.Terminating,
+		}
+
+		if endpoint.Hostname != nil {
+			data.Hostname = *endpoint.Hostname
+		}
+		if endpoint.NodeName != nil {
+			data.NodeName = *endpoint.NodeName
+		}
+		if endpoint.Zone != nil {
+			data.Zone = *endpoint.Zone
+		}
+		if endpoint.TargetRef != nil {
+			data.TargetRefKind = endpoint.TargetRef.Kind
+			data.TargetRef = endpoint.TargetRef.Name
+		}
+
+		if endpoint.Hints != nil {
+			for _, zone := range endpoint.Hints.ForZones {
+				data.HintsForZones = append(data.HintsForZones, zone.Name)
+			}
+			for _, node := range endpoint.Hints.ForNodes {
+				data.HintsForNodes = append(data.HintsForNodes, node.Name)
+			}
+		}
+
+		// A nil ready or serving condition means the state is unknown and should be interpreted as true
+		if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+			readyCount++
+		}
+		if endpoint.Conditions.Serving == nil || *endpoint.Conditions.Serving {
+			servingCount++
+		}
+		if endpoint.Conditions.Terminating != nil && *endpoint.Conditions.Terminating {
+			terminatingCount++
+		}
+
+		endpoints = append(endpoints, data)
+	}
+
+	ports := []types.EndpointPortData{}
+	for _, port := range slice.Ports {
+		portData := types.EndpointPortData{}
+		if port.Name != nil {
+			portData.Name = *port.Name
+		}
+		if port.Protocol != nil {
+			portData.Protocol = string(*port.Protocol)
+		}
+		if port.Port != nil {
+			portData.Port = *port.Port
+		}
+		ports = append(ports, portData)
+	}
+
+	createdByKind, createdByName := utils.GetOwnerReferenceInfo(slice)
+
+	return types.EndpointSliceData{
+		LogEntryMetadata: types.LogEntryMetadata{
+			Timestamp:        time.Now(),
+			ResourceType:     "endpointslice",
+			Name:             utils.ExtractName(slice),
+			Namespace:        utils.ExtractNamespace(slice),
+			CreatedTimestamp: utils.ExtractCreationTimestamp(slice),
+			Labels:           utils.ExtractLabels(slice),
+			Annotations:      utils.ExtractAnnotations(slice),
+			CreatedByKind:    createdByKind,
+			CreatedByName:    createdByName,
+		},
+		ServiceName:               slice.Labels[discoveryv1.LabelServiceName],
+		AddressType:               string(slice.AddressType),
+		ManagedBy:                 slice.Labels[discoveryv1.LabelManagedBy],
+		Endpoints:                 endpoints,
+		Ports:                     ports,
+		EndpointsCount:            len(slice.Endpoints),
+		ReadyEndpointsCount:       readyCount,
+		ServingEndpointsCount:     servingCount,
+		TerminatingEndpointsCount: terminatingCount,
+	}
+}
@@ -0,0 +1,205 @@
+package resources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	testutils "go.goms.io/aks/kube-state-logs/pkg/collector/testutils"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+	"go.goms.io/aks/kube-state-logs/pkg/utils"
+)
+
+// createTestEndpointSlice creates a test EndpointSlice with a ready and a terminating endpoint
+func createTestEndpointSlice(name, namespace string) *discoveryv1.EndpointSlice {
+	portName := "http"
+	port := int32(8080)
+	protocol := corev1.ProtocolTCP
+	nodeName := "node-1"
+	zone := "zone-a"
+	hostname := "web-0"
+	ready := true
+	notReady := false
+	terminating := true
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName: "web",
+				discoveryv1.LabelManagedBy:   "endpointslice-controller.k8s.io",
+			},
+			CreationTimestamp: metav1.Now(),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "v1",
+					Kind:       "Service",
+					Name:       "web",
+					UID:        "test-uid",
+				},
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{
+				Addresses: []string{"10.0.0.1"},
+				Hostname:  &hostname,
+				NodeName:  &nodeName,
+				Zone:      &zone,
+				Conditions: discoveryv1.EndpointConditions{
+					Ready:   &ready,
+					Serving: &ready,
+				},
+				TargetRef: &corev1.ObjectReference{Kind: "Pod", Namespace: namespace, Name: "web-0"},
+				Hints: &discoveryv1.EndpointHints{
+					ForZones: []discoveryv1.ForZone{{Name: "zone-a"}},
+					ForNodes: []discoveryv1.ForNode{{Name: "node-1"}},
+				},
+			},
+			{
+				Addresses: []string{"10.0.0.2"},
+				Conditions: discoveryv1.EndpointConditions{
+					Ready:       &notReady,
+					Serving:     &ready,
+					Terminating: &terminating,
+				},
+			},
+		},
+		Ports: []discoveryv1.EndpointPort{
+			{
+				Name:     &portName,
+				Protocol: &protocol,
+				Port:     &port,
+			},
+		},
+	}
+}
+
+func TestNewEndpointSliceHandler(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	handler := NewEndpointSliceHandler(client)
+	if handler == nil {
+		t.Fatal("Expected handler to be created, got nil")
+	}
+	if handler.BaseHandler == (utils.BaseHandler{}) {
+		t.Error("Expected BaseHandler to be embedded")
+	}
+}
+
+func TestEndpointSliceHandler_Collect(t *testing.T) {
+	slice1 := createTestEndpointSlice("web-abc12", "default")
+	slice2 := createTestEndpointSlice("web-def34", "kube-system")
+	client := fake.NewSimpleClientset(slice1, slice2)
+	handler := NewEndpointSliceHandler(client)
+	factory := informers.NewSharedInformerFactory(client, time.Hour)
+	logger := &testutils.MockLogger{}
+	err := handler.SetupInformer(factory, logger, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to setup informer: %v", err)
+	}
+	factory.Start(nil)
+	factory.WaitForCacheSync(nil)
+	ctx := context.Background()
+	entries, err := handler.Collect(ctx, []string{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	entries, err = handler.Collect(ctx, []string{"default"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry for default namespace, got %d", len(entries))
+	}
+	entry, ok := entries[0].(types.EndpointSliceData)
+	if !ok {
+		t.Fatalf("Expected EndpointSliceData type, got %T", entries[0])
+	}
+	if entry.Namespace != "default" {
+		t.Errorf("Expected namespace 'default', got '%s'", entry.Namespace)
+	}
+}
+
+func TestEndpointSliceHandler_createLogEntry(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	handler := NewEndpointSliceHandler(client)
+	slice := createTestEndpointSlice("web-abc12", "default")
+	entry := handler.createLogEntry(slice)
+
+	if entry.ResourceType != "endpointslice" {
+		t.Errorf("Expected resource type 'endpointslice', got '%s'", entry.ResourceType)
+	}
+	if entry.ServiceName != "web" {
+		t.Errorf("Expected service name 'web', got '%s'", entry.ServiceName)
+	}
+	if entry.AddressType != "IPv4" {
+		t.Errorf("Expected address type 'IPv4', got '%s'", entry.AddressType)
+	}
+	if entry.ManagedBy != "endpointslice-controller.k8s.io" {
+		t.Errorf("Expected managed by 'endpointslice-controller.k8s.io', got '%s'", entry.ManagedBy)
+	}
+	if entry.CreatedByKind != "Service" || entry.CreatedByName != "web" {
+		t.Errorf("Expected created by Service/web, got %s/%s", entry.CreatedByKind, entry.CreatedByName)
+	}
+	if entry.EndpointsCount != 2 || entry.ReadyEndpointsCount != 1 || entry.ServingEndpointsCount != 2 || entry.TerminatingEndpointsCount != 1 {
+		t.Errorf("Unexpected endpoint counts: total=%d ready=%d serving=%d terminating=%d",
+			entry.EndpointsCount, entry.ReadyEndpointsCount, entry.ServingEndpointsCount, entry.TerminatingEndpointsCount)
+	}
+	if len(entry.Ports) != 1 || entry.Ports[0].Name != "http" || entry.Ports[0].Port != 8080 || entry.Ports[0].Protocol != "TCP" {
+		t.Errorf("Unexpected ports: %+v", entry.Ports)
+	}
+
+	if len(entry.Endpoints) != 2 {
+		t.Fatalf("Expected 2 endpoints, got %d", len(entry.Endpoints))
+	}
+	endpoint := entry.Endpoints[0]
+	if endpoint.NodeName != "node-1" || endpoint.Zone != "zone-a" || endpoint.Hostname != "web-0" {
+		t.Errorf("Unexpected endpoint topology: node=%s zone=%s hostname=%s", endpoint.NodeName, endpoint.Zone, endpoint.Hostname)
+	}
+	if endpoint.TargetRefKind != "Pod" || endpoint.TargetRef != "web-0" {
+		t.Errorf("Expected target Pod/web-0, got %s/%s", endpoint.TargetRefKind, endpoint.TargetRef)
+	}
+	if len(endpoint.HintsForZones) != 1 || endpoint.HintsForZones[0] != "zone-a" {
+		t.Errorf("Expected zone hint 'zone-a', got %v", endpoint.HintsForZones)
+	}
+	if len(endpoint.HintsForNodes) != 1 || endpoint.HintsForNodes[0] != "node-1" {
+		t.Errorf("Expected node hint 'node-1', got %v", endpoint.HintsForNodes)
+	}
+	if endpoint.Ready == nil || !*endpoint.Ready {
+		t.Error("Expected first endpoint to be ready")
+	}
+	if terminating := entry.Endpoints[1].Terminating; terminating == nil || !*terminating {
+		t.Error("Expected second endpoint to be terminating")
+	}
+}
+
+func TestEndpointSliceHandler_createLogEntry_Empty(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	handler := NewEndpointSliceHandler(client)
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "empty",
+			Namespace: "default",
+		},
+		AddressType: discoveryv1.AddressTypeIPv6,
+	}
+	entry := handler.createLogEntry(slice)
+	if entry.Endpoints == nil || len(entry.Endpoints) != 0 {
+		t.Errorf("Expected empty endpoints slice, got %v", entry.Endpoints)
+	}
+	if entry.Ports == nil || len(entry.Ports) != 0 {
+		t.Errorf("Expected empty ports slice, got %v", entry.Ports)
+	}
+	if entry.ServiceName != "" {
+		t.Errorf("Expected empty service name, got '%s'", entry.ServiceName)
+	}
+}
@@ -0,0 +1,176 @@
+package resources
+
+import (
+	"context"
+	"time"
+
+	eventsv1 "k8s.io/api/events/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"go.goms.io/aks/kube-state-logs/pkg/interfaces"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+	"go.goms.io/aks/kube-state-logs/pkg/utils"
+)
+
+// EventHandler handles collection of Kubernetes events
+type EventHandler struct {
+	utils.BaseHandler
+	seenCache cache.ThreadSafeStore
+}
+
+// NewEventHandler creates a new EventHandler
+func NewEventHandler(client kubernetes.Interface) *EventHandler {
+	return &EventHandler{
+		BaseHandler: utils.NewBaseHandler(client),
+		seenCache:   cache.NewThreadSafeStore(cache.Indexers{}, cache.Indices{}),
+	}
+}
+
+// SetupInformer sets up the event informer
+func (h *EventHandler) SetupInformer(factory informers.SharedInformerFactory, logger interfaces.Logger, resyncPeriod time.Duration) error {
+	// Create event informer (events.k8s.io also serves events created through core/v1)
+	informer := factory.Events().V1().Events().Informer()
+	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
+	return nil
+}
+
+// EnableDeletionLogging is a no-op for events, which are deleted when their TTL expires
+func (h *EventHandler) EnableDeletionLogging(namespaces []string) error {
+	return nil
+}
+
+// Collect gathers events that are new or have recurred since the last collection (uses cache)
+func (h *EventHandler) Collect(ctx context.Context, namespaces []string) ([]any, error) {
+	var entries []any
+	listTime := time.Now()
+
+	h.forEachEvent(namespaces, func(event *eventsv1.Event, count int32) {
+		// Only log events that were not seen before or whose count changed
+		if previousCount, exists := h.seenCache.Get(string(event.UID)); exists && previousCount.(int32) == count {
+			return
+		}
+
+		entry := h.createLogEntry(event)
+		entry.Timestamp = listTime
+		entries = append(entries, entry)
+	})
+	return entries, nil
+}
+
+// MarkSeen records the events in the cache as logged without logging them
+func (h *EventHandler) MarkSeen(namespaces []string) {
+	h.forEachEvent(namespaces, func(*eventsv1.Event, int32) {})
+}
+
+// forEachEvent calls fn with each cached event in the given namespaces and its count, then
+// records the counts in the seen cache
+func (h *EventHandler) forEachEvent(namespaces []string, fn func(event *eventsv1.Event, count int32)) {
+	currentCounts := make(map[string]int32)
+
+	for _, obj := range utils.SafeGetStoreList(h.GetInformer()) {
+		event, ok := obj.(*eventsv1.Event)
+		if !ok {
+			continue
+		}
+
+		if !utils.ShouldIncludeNamespace(namespaces, event.Namespace) {
+			continue
+		}
+
+		count := h.getEventCount(event)
+		currentCounts[string(event.UID)] = count
+		fn(event, count)
+	}
+
+	// Update seen cache and cleanup expired events
+	h.updateSeenCache(currentCounts)
+}
+
+// getEventCount returns how many times the event has occurred
+func (h *EventHandler) getEventCount(event *eventsv1.Event) int32 {
+	if event.Series != nil {
+		return event.Series.Count
+	}
+	if event.DeprecatedCount > 0 {
+		return event.DeprecatedCount
+	}
+	return 1
+}
+
+// updateSeenCache records the current event counts and removes events that no longer exist
+func (h *EventHandler) updateSeenCache(currentCounts map[string]int32) {
+	for _, key := range h.seenCache.ListKeys() {
+		if _, exists := currentCounts[key]; !exists {
+			h.seenCache.Delete(key)
+		}
+	}
+
+	for key, count := range currentCounts {
+		h.seenCache.Add(key, count)
+	}
+}
+
+// createLogEntry creates an EventData from an event
+func (h *EventHandler) createLogEntry(event *eventsv1.Event) types.EventData {
+	// First occurrence: prefer the deprecated core/v1 timestamp, then the event time
+	var firstTimestamp *time.Time
+	if !event.DeprecatedFirstTimestamp.IsZero() {
+		firstTimestamp = &event.DeprecatedFirstTimestamp.Time
+	} else if !event.EventTime.IsZero() {
+		firstTimestamp = &event.EventTime.Time
+	}
+
+	// Last occurrence: series observation, then the deprecated core/v1 timestamp, then the first occurrence
+	lastTimestamp := firstTimestamp
+	if event.Series != nil && !event.Series.LastObservedTime.IsZero() {
+		lastTimestamp = &event.Series.LastObservedTime.Time
+	} else if !event.DeprecatedLastTimestamp.IsZero() {
+		lastTimestamp = &event.DeprecatedLastTimestamp.Time
+	}
+
+	// Fall back to the deprecated source for events recorded through core/v1
+	reportingController := event.ReportingController
+	if reportingController == "" {
+		reportingController = event.DeprecatedSource.Component
+	}
+	reportingInstance := event.ReportingInstance
+	if reportingInstance == "" {
+		reportingInstance = event.DeprecatedSource.Host
+	}
+
+	createdByKind, createdByName := utils.GetOwnerReferenceInfo(event)
+
+	data := types.EventData{
+		LogEntryMetadata: types.LogEntryMetadata{
+			Timestamp:        time.Now(),
+			ResourceType:     "event",
+			Name:             utils.ExtractName(event),
+			Namespace:        utils.ExtractNamespace(event),
+			CreatedTimestamp: utils.ExtractCreationTimestamp(event),
+			Labels:           utils.ExtractLabels(event),
+			Annotations:      utils.ExtractAnnotations(event),
+			CreatedByKind:    createdByKind,
+			CreatedByName:    createdByName,
+		},
+		InvolvedObjectKind:       event.Regarding.Kind,
+		InvolvedObjectAPIVersion: event.Regarding.APIVersion,
+		InvolvedObjectNamespace:  event.Regarding.Namespace,
+		InvolvedObjectName:       event.Regarding.Name,
+		InvolvedObjectUID:        string(event.Regarding.UID),
+		InvolvedObjectFieldPath:  event.Regarding.FieldPath,
+		Reason:                   event.Reason,
+		Message:                  event.Note,
+		Type:                     event.Type,
+		Action:                   event.Action,
+		Count:                    h.getEventCount(event),
+		FirstTimestamp:           firstTimestamp,
+		LastTimestamp:            lastTimestamp,
+		ReportingController:      reportingController,
+		ReportingInstance:        reportingInstance,
+	}
+
+	return data
+}
@@ -0,0 +1,182 @@
+package resources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	testutils "go.goms.io/aks/kube-state-logs/pkg/collector/testutils"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// createTestEvent creates a test Event about a pod
+func createTestEvent(name, namespace string) *eventsv1.Event {
+	now := metav1.Now()
+	return &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			UID:               k8stypes.UID("uid-" + name),
+			CreationTimestamp: now,
+		},
+		EventTime:           metav1.NewMicroTime(now.Time),
+		ReportingController: "default-scheduler",
+		ReportingInstance:   "default-scheduler-node-1",
+		Action:              "Scheduling",
+		Reason:              "FailedScheduling",
+		Note:                "0/3 nodes are available: 3 Insufficient cpu.",
+		Type:                corev1.EventTypeWarning,
+		Regarding: corev1.ObjectReference{
+			Kind:       "Pod",
+			APIVersion: "v1",
+			Namespace:  namespace,
+			Name:       "test-pod",
+			UID:        "pod-uid",
+		},
+	}
+}
+
+func TestEventHandler_Collect(t *testing.T) {
+	event1 := createTestEvent("test-event-1", "default")
+	event2 := createTestEvent("test-event-2", "kube-system")
+	client := fake.NewSimpleClientset(event1, event2)
+	handler := NewEventHandler(client)
+	factory := informers.NewSharedInformerFactory(client, time.Hour)
+	logger := &testutils.MockLogger{}
+	err := handler.SetupInformer(factory, logger, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to setup informer: %v", err)
+	}
+	factory.Start(nil)
+	factory.WaitForCacheSync(nil)
+	ctx := context.Background()
+	entries, err := handler.Collect(ctx, []string{"default"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry for default namespace, got %d", len(entries))
+	}
+	eventData, ok := entries[0].(types.EventData)
+	if !ok {
+		t.Fatalf("Expected EventData type, got %T", entries[0])
+	}
+	if eventData.Namespace != "default" {
+		t.Errorf("Expected namespace 'default', got '%s'", eventData.Namespace)
+	}
+}
+
+func TestEventHandler_Collect_Deduplication(t *testing.T) {
+	event := createTestEvent("test-event", "default")
+	client := fake.NewSimpleClientset(event)
+	handler := NewEventHandler(client)
+	factory := informers.NewSharedInformerFactory(client, time.Hour)
+	logger := &testutils.MockLogger{}
+	err := handler.SetupInformer(factory, logger, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to setup informer: %v", err)
+	}
+	factory.Start(nil)
+	factory.WaitForCacheSync(nil)
+	ctx := context.Background()
+
+	entries, err := handler.Collect(ctx, []string{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry on first collection, got %d", len(entries))
+	}
+
+	entries, err = handler.Collect(ctx, []string{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("Expected event not to be re-logged, got %d entries", len(entries))
+	}
+
+	// A recurring event is logged again with its new count
+	recurred := event.DeepCopy()
+	recurred.Series = &eventsv1.EventSeries{Count: 2, LastObservedTime: metav1.NowMicro()}
+	if err := handler.GetInformer().GetStore().Update(recurred); err != nil {
+		t.Fatalf("Failed to update store: %v", err)
+	}
+
+	entries, err = handler.Collect(ctx, []string{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected recurring event to be logged again, got %d entries", len(entries))
+	}
+	if count := entries[0].(types.EventData).Count; count != 2 {
+		t.Errorf("Expected count 2, got %d", count)
+	}
+}
+
+func TestEventHandler_createLogEntry(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	handler := NewEventHandler(client)
+	event := createTestEvent("test-event", "default")
+	entry := handler.createLogEntry(event)
+	if entry.ResourceType != "event" {
+		t.Errorf("Expected resource type 'event', got '%s'", entry.ResourceType)
+	}
+	if entry.InvolvedObjectKind != "Pod" || entry.InvolvedObjectName != "test-pod" {
+		t.Errorf("Expected involved object Pod/test-pod, got %s/%s", entry.InvolvedObjectKind, entry.InvolvedObjectName)
+	}
+	if entry.Reason != "FailedScheduling" {
+		t.Errorf("Expected reason 'FailedScheduling', got '%s'", entry.Reason)
+	}
+	if entry.Message != "0/3 nodes are available: 3 Insufficient cpu." {
+		t.Errorf("Expected message to be the event note, got '%s'", entry.Message)
+	}
+	if entry.Type != corev1.EventTypeWarning {
+		t.Errorf("Expected type 'Warning', got '%s'", entry.Type)
+	}
+	if entry.Count != 1 {
+		t.Errorf("Expected count 1, got %d", entry.Count)
+	}
+	if entry.ReportingController != "default-scheduler" {
+		t.Errorf("Expected reporting controller 'default-scheduler', got '%s'", entry.ReportingController)
+	}
+	if entry.FirstTimestamp == nil || entry.LastTimestamp == nil {
+		t.Error("Expected first and last timestamps to be set")
+	}
+}
+
+func TestEventHandler_createLogEntry_CoreV1Fields(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	handler := NewEventHandler(client)
+	event := createTestEvent("test-event", "default")
+	first := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	last := metav1.NewTime(time.Now().Add(-1 * time.Minute))
+	event.EventTime = metav1.MicroTime{}
+	event.ReportingController = ""
+	event.ReportingInstance = ""
+	event.DeprecatedSource = corev1.EventSource{Component: "kubelet", Host: "node-1"}
+	event.DeprecatedFirstTimestamp = first
+	event.DeprecatedLastTimestamp = last
+	event.DeprecatedCount = 5
+	entry := handler.createLogEntry(event)
+	if entry.Count != 5 {
+		t.Errorf("Expected count 5, got %d", entry.Count)
+	}
+	if entry.ReportingController != "kubelet" || entry.ReportingInstance != "node-1" {
+		t.Errorf("Expected source kubelet/node-1, got %s/%s", entry.ReportingController, entry.ReportingInstance)
+	}
+	if entry.FirstTimestamp == nil || !entry.FirstTimestamp.Equal(first.Time) {
+		t.Errorf("Expected first timestamp %v, got %v", first.Time, entry.FirstTimestamp)
+	}
+	if entry.LastTimestamp == nil || !entry.LastTimestamp.Equal(last.Time) {
+		t.Errorf("Expected last timestamp %v, got %v", last.Time, entry.LastTimestamp)
+	}
+}
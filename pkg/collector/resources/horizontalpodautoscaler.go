@@ -30,6 +30,7 @@ func (h *HorizontalPodAutoscalerHandler) SetupInformer(factory informers.SharedI
 	// Create horizontalpodautoscaler informer
 	informer := factory.Autoscaling().V2().HorizontalPodAutoscalers().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
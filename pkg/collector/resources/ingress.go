@@ -31,6 +31,7 @@ func (h *IngressHandler) SetupInformer(factory informers.SharedInformerFactory,
 	// Create ingress informer
 	informer := factory.Networking().V1().Ingresses().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -30,6 +30,7 @@ func (h *IngressClassHandler) SetupInformer(factory informers.SharedInformerFact
 	// Create ingressclass informer
 	informer := factory.Networking().V1().IngressClasses().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -30,6 +30,7 @@ func (h *JobHandler) SetupInformer(factory informers.SharedInformerFactory, logg
 	// Create job informer
 	informer := factory.Batch().V1().Jobs().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
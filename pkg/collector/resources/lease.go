@@ -30,6 +30,7 @@ func (h *LeaseHandler) SetupInformer(factory informers.SharedInformerFactory, lo
 	// Create lease informer
 	informer := factory.Coordination().V1().Leases().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
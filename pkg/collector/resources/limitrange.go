@@ -30,6 +30,7 @@ func (h *LimitRangeHandler) SetupInformer(factory informers.SharedInformerFactor
 	// Create limitrange informer
 	informer := factory.Core().V1().LimitRanges().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
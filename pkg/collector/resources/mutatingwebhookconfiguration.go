@@ -30,6 +30,7 @@ func (h *MutatingWebhookConfigurationHandler) SetupInformer(factory informers.Sh
 	// Create mutatingwebhookconfiguration informer
 	informer := factory.Admissionregistration().V1().MutatingWebhookConfigurations().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
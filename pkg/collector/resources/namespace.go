@@ -31,6 +31,7 @@ func (h *NamespaceHandler) SetupInformer(factory informers.SharedInformerFactory
 	// Create namespace informer
 	informer := factory.Core().V1().Namespaces().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -30,6 +30,7 @@ func (h *NetworkPolicyHandler) SetupInformer(factory informers.SharedInformerFac
 	// Create networkpolicy informer
 	informer := factory.Networking().V1().NetworkPolicies().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -31,6 +31,7 @@ func (h *NodeHandler) SetupInformer(factory informers.SharedInformerFactory, log
 	// Create node informer
 	informer := factory.Core().V1().Nodes().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -30,6 +30,7 @@ func (h *PersistentVolumeHandler) SetupInformer(factory informers.SharedInformer
 	// Create persistentvolume informer
 	informer := factory.Core().V1().PersistentVolumes().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
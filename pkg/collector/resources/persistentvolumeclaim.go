@@ -30,6 +30,7 @@ func (h *PersistentVolumeClaimHandler) SetupInformer(factory informers.SharedInf
 	// Create persistentvolumeclaim informer
 	informer := factory.Core().V1().PersistentVolumeClaims().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
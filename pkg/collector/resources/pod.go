@@ -31,6 +31,7 @@ func (h *PodHandler) SetupInformer(factory informers.SharedInformerFactory, logg
 	// Create pod informer
 	informer := factory.Core().V1().Pods().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -30,6 +30,7 @@ func (h *PodDisruptionBudgetHandler) SetupInformer(factory informers.SharedInfor
 	// Create poddisruptionbudget informer
 	informer := factory.Policy().V1().PodDisruptionBudgets().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
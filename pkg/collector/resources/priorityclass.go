@@ -30,6 +30,7 @@ func (h *PriorityClassHandler) SetupInformer(factory informers.SharedInformerFac
 	// Create priorityclass informer
 	informer := factory.Scheduling().V1().PriorityClasses().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -30,6 +30,7 @@ func (h *ReplicaSetHandler) SetupInformer(factory informers.SharedInformerFactor
 	// Create replicaset informer
 	informer := factory.Apps().V1().ReplicaSets().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
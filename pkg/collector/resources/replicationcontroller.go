@@ -30,6 +30,7 @@ func (h *ReplicationControllerHandler) SetupInformer(factory informers.SharedInf
 	// Create replicationcontroller informer
 	informer := factory.Core().V1().ReplicationControllers().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
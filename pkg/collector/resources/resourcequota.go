@@ -30,6 +30,7 @@ func (h *ResourceQuotaHandler) SetupInformer(factory informers.SharedInformerFac
 	// Create resourcequota informer
 	informer := factory.Core().V1().ResourceQuotas().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
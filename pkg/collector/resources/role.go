@@ -30,6 +30,7 @@ func (h *RoleHandler) SetupInformer(factory informers.SharedInformerFactory, log
 	// Create role informer
 	informer := factory.Rbac().V1().Roles().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -30,6 +30,7 @@ func (h *RoleBindingHandler) SetupInformer(factory informers.SharedInformerFacto
 	// Create rolebinding informer
 	informer := factory.Rbac().V1().RoleBindings().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -30,6 +30,7 @@ func (h *RuntimeClassHandler) SetupInformer(factory informers.SharedInformerFact
 	// Create runtimeclass informer
 	informer := factory.Node().V1().RuntimeClasses().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
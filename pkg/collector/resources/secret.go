@@ -31,6 +31,7 @@ func (h *SecretHandler) SetupInformer(factory informers.SharedInformerFactory, l
 	// Create secret informer
 	informer := factory.Core().V1().Secrets().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
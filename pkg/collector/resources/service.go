@@ -5,6 +5,7 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
@@ -18,7 +19,7 @@ import (
 // ServiceHandler handles collection of service metrics
 type ServiceHandler struct {
 	utils.BaseHandler
-	endpointsInformer cache.SharedIndexInformer
+	endpointSliceInformer cache.SharedIndexInformer
 }
 
 // NewServiceHandler creates a new ServiceHandler
@@ -33,9 +34,10 @@ func (h *ServiceHandler) SetupInformer(factory informers.SharedInformerFactory,
 	// Create service informer
 	serviceInformer := factory.Core().V1().Services().Informer()
 	h.SetupBaseInformer(serviceInformer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 
-	// Create endpoints informer
-	h.endpointsInformer = factory.Core().V1().Endpoints().Informer()
+	// Create endpointslice informer (core/v1 Endpoints are truncated at 1000 addresses)
+	h.endpointSliceInformer = factory.Discovery().V1().EndpointSlices().Informer()
 
 	return nil
 }
@@ -166,29 +168,41 @@ func (h *ServiceHandler) createLogEntry(service *corev1.Service) types.ServiceDa
 	return data
 }
 
-// countEndpointsForService counts the number of endpoints for a given service
+// countEndpointsForService counts the number of ready endpoints across all endpoint slices of a given service
 func (h *ServiceHandler) countEndpointsForService(namespace, serviceName string) int {
-	// Get all endpoints from the cache
-	endpoints := utils.SafeGetStoreList(h.endpointsInformer)
+	// Get all endpoint slices from the cache
+	endpointSlices := utils.SafeGetStoreList(h.endpointSliceInformer)
 
-	for _, obj := range endpoints {
-		endpoint, ok := obj.(*corev1.Endpoints)
+	// Dual-stack services have one slice per address family, so endpoints are deduplicated by target
+	readyEndpoints := make(map[string]struct{})
+	for _, obj := range endpointSlices {
+		slice, ok := obj.(*discoveryv1.EndpointSlice)
 		if !ok {
 			continue
 		}
 
-		// Check if this endpoint matches the service
-		if endpoint.Namespace == namespace && endpoint.Name == serviceName {
-			// Count all addresses across all subsets
-			totalAddresses := 0
-			for _, subset := range endpoint.Subsets {
-				if subset.Addresses != nil {
-					totalAddresses += len(subset.Addresses)
-				}
+		// Check if this slice belongs to the service
+		if slice.Namespace != namespace || slice.Labels[discoveryv1.LabelServiceName] != serviceName {
+			continue
+		}
+
+		for _, endpoint := range slice.Endpoints {
+			// A nil ready condition means the state is unknown and should be interpreted as ready
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+
+			var key string
+			if endpoint.TargetRef != nil {
+				key = endpoint.TargetRef.Kind + "/" + endpoint.TargetRef.Namespace + "/" + endpoint.TargetRef.Name
+			} else if len(endpoint.Addresses) > 0 {
+				key = string(slice.AddressType) + "/" + endpoint.Addresses[0]
+			} else {
+				continue
 			}
-			return totalAddresses
+			readyEndpoints[key] = struct{}{}
 		}
 	}
 
-	return 0
+	return len(readyEndpoints)
 }
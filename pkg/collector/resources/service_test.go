@@ -2,10 +2,12 @@ package resources
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/informers"
@@ -90,26 +92,44 @@ func createTestServiceEndpoints(name, namespace string, serviceType corev1.Servi
 	return service
 }
 
-// createTestEndpointsForService creates test Endpoints for a service
-func createTestEndpointsForService(name, namespace string, addresses int) *corev1.Endpoints {
-	endpoints := &corev1.Endpoints{
+// createTestEndpointSliceForService creates a test EndpointSlice for a service
+func createTestEndpointSliceForService(name, namespace, serviceName string, addressType discoveryv1.AddressType, readyAddresses, notReadyAddresses int) *discoveryv1.EndpointSlice {
+	portName := "http"
+	port := int32(8080)
+	ready := true
+	notReady := false
+	slice := &discoveryv1.EndpointSlice{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName: serviceName,
+			},
 		},
-		Subsets: []corev1.EndpointSubset{
+		AddressType: addressType,
+		Ports: []discoveryv1.EndpointPort{
 			{
-				Addresses: make([]corev1.EndpointAddress, addresses),
-				Ports: []corev1.EndpointPort{
-					{
-						Name: "http",
-						Port: 8080,
-					},
-				},
+				Name: &portName,
+				Port: &port,
 			},
 		},
 	}
-	return endpoints
+	for i := 0; i < readyAddresses+notReadyAddresses; i++ {
+		endpoint := discoveryv1.Endpoint{
+			Addresses:  []string{fmt.Sprintf("%s-%d", addressType, i)},
+			Conditions: discoveryv1.EndpointConditions{Ready: &ready},
+			TargetRef: &corev1.ObjectReference{
+				Kind:      "Pod",
+				Namespace: namespace,
+				Name:      fmt.Sprintf("%s-pod-%d", serviceName, i),
+			},
+		}
+		if i >= readyAddresses {
+			endpoint.Conditions.Ready = &notReady
+		}
+		slice.Endpoints = append(slice.Endpoints, endpoint)
+	}
+	return slice
 }
 
 func TestNewServiceHandler(t *testing.T) {
@@ -135,8 +155,8 @@ func TestServiceHandler_SetupInformer(t *testing.T) {
 	if handler.GetInformer() == nil {
 		t.Error("Expected informer to be set up")
 	}
-	if handler.endpointsInformer == nil {
-		t.Error("Expected endpoints informer to be set up")
+	if handler.endpointSliceInformer == nil {
+		t.Error("Expected endpointslice informer to be set up")
 	}
 }
 
@@ -388,18 +408,22 @@ func TestServiceHandler_countEndpointsForService(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to setup informer: %v", err)
 	}
-	// Create endpoints before starting informers
-	endpoints := createTestEndpointsForService("test-service", "default", 3)
-	_, err = client.CoreV1().Endpoints("default").Create(context.Background(), endpoints, metav1.CreateOptions{})
-	if err != nil {
-		t.Fatalf("Failed to create endpoints: %v", err)
+	// Create dual-stack endpoint slices before starting informers; both families point at the same pods
+	for _, slice := range []*discoveryv1.EndpointSlice{
+		createTestEndpointSliceForService("test-service-ipv4", "default", "test-service", discoveryv1.AddressTypeIPv4, 3, 1),
+		createTestEndpointSliceForService("test-service-ipv6", "default", "test-service", discoveryv1.AddressTypeIPv6, 3, 1),
+	} {
+		_, err = client.DiscoveryV1().EndpointSlices("default").Create(context.Background(), slice, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Failed to create endpoint slice: %v", err)
+		}
 	}
 	factory.Start(nil)
 	factory.WaitForCacheSync(nil)
 	// Test with endpoints
 	count := handler.countEndpointsForService("default", "test-service")
 	if count != 3 {
-		t.Errorf("Expected 3 endpoints, got %d", count)
+		t.Errorf("Expected 3 ready endpoints, got %d", count)
 	}
 	// Test with no endpoints
 	count = handler.countEndpointsForService("default", "nonexistent-service")
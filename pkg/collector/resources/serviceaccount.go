@@ -30,6 +30,7 @@ func (h *ServiceAccountHandler) SetupInformer(factory informers.SharedInformerFa
 	// Create serviceaccount informer
 	informer := factory.Core().V1().ServiceAccounts().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -30,6 +30,7 @@ func (h *StatefulSetHandler) SetupInformer(factory informers.SharedInformerFacto
 	// Create statefulset informer
 	informer := factory.Apps().V1().StatefulSets().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -30,6 +30,7 @@ func (h *StorageClassHandler) SetupInformer(factory informers.SharedInformerFact
 	// Create storageclass informer
 	informer := factory.Storage().V1().StorageClasses().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -30,6 +30,7 @@ func (h *ValidatingAdmissionPolicyHandler) SetupInformer(factory informers.Share
 	// Create validatingadmissionpolicy informer
 	informer := factory.Admissionregistration().V1beta1().ValidatingAdmissionPolicies().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -30,6 +30,7 @@ func (h *ValidatingAdmissionPolicyBindingHandler) SetupInformer(factory informer
 	// Create validatingadmissionpolicybinding informer
 	informer := factory.Admissionregistration().V1beta1().ValidatingAdmissionPolicyBindings().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
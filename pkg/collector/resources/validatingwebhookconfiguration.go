@@ -30,6 +30,7 @@ func (h *ValidatingWebhookConfigurationHandler) SetupInformer(factory informers.
 	// Create validatingwebhookconfiguration informer
 	informer := factory.Admissionregistration().V1().ValidatingWebhookConfigurations().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -30,6 +30,7 @@ func (h *VolumeAttachmentHandler) SetupInformer(factory informers.SharedInformer
 	// Create volumeattachment informer
 	informer := factory.Storage().V1().VolumeAttachments().Informer()
 	h.SetupBaseInformer(informer, logger)
+	h.SetEntryFunc(utils.NewEntryFunc(h.createLogEntry))
 	return nil
 }
 
@@ -0,0 +1,94 @@
+package collector
+
+import (
+	"hash/fnv"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/klog/v2"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+)
+
+// shardFilter decides which objects belong to the shard of this replica
+type shardFilter struct {
+	config.ShardConfig
+}
+
+// owns reports whether an object belongs to this shard. Namespaced objects are assigned by a
+// hash of their shard key, cluster-scoped objects all belong to one shard.
+func (f *shardFilter) owns(obj metav1.Object) bool {
+	if obj.GetNamespace() == "" {
+		return f.Shard == f.ClusterScopedShard
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(f.key(obj)))
+	return hash.Sum64()%uint64(f.TotalShards) == uint64(f.Shard)
+}
+
+// key returns the key a namespaced object is assigned to a shard by: its namespace, or its UID.
+// By UID, services and their EndpointSlices are assigned by the namespace and name of the
+// service instead, so that the shard of a service caches the slices its endpoints are counted from.
+func (f *shardFilter) key(obj metav1.Object) string {
+	if f.By != config.ShardByUID {
+		return obj.GetNamespace()
+	}
+	switch obj.(type) {
+	case *corev1.Service:
+		return obj.GetNamespace() + "/" + obj.GetName()
+	case *discoveryv1.EndpointSlice:
+		if service, exists := obj.GetLabels()[discoveryv1.LabelServiceName]; exists {
+			return obj.GetNamespace() + "/" + service
+		}
+	}
+	return string(obj.GetUID())
+}
+
+// transform replaces objects of other shards in the informer caches by their metadata.
+// Handlers skip these because they are not of the type they expect, so only objects of this
+// shard are logged, while the caches hold little more than the keys of the others.
+func (f *shardFilter) transform(obj any) (any, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil || f.owns(accessor) {
+		return obj, nil
+	}
+	return &metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            accessor.GetName(),
+			Namespace:       accessor.GetNamespace(),
+			UID:             accessor.GetUID(),
+			ResourceVersion: accessor.GetResourceVersion(),
+		},
+	}, nil
+}
+
+// informerFactoryOptions returns the options of the shared informer factory for this shard
+func (f *shardFilter) informerFactoryOptions() []informers.SharedInformerOption {
+	if f == nil {
+		return nil
+	}
+	return []informers.SharedInformerOption{informers.WithTransform(f.transform)}
+}
+
+// shardedDynamicFactory sets the shard transform on the informers of a dynamic informer
+// factory, which has no option for it
+type shardedDynamicFactory struct {
+	dynamicinformer.DynamicSharedInformerFactory
+	filter *shardFilter
+}
+
+// ForResource returns the informer of a resource, with the shard transform set
+func (f shardedDynamicFactory) ForResource(gvr schema.GroupVersionResource) informers.GenericInformer {
+	informer := f.DynamicSharedInformerFactory.ForResource(gvr)
+	// Setting the transform fails once the informer runs, when it was set before
+	if err := informer.Informer().SetTransform(f.filter.transform); err != nil {
+		klog.V(2).Infof("Not setting shard transform for %v: %v", gvr, err)
+	}
+	return informer
+}
@@ -0,0 +1,167 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go.goms.io/aks/kube-state-logs/pkg/collector/resources"
+	"go.goms.io/aks/kube-state-logs/pkg/collector/testutils"
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+func TestShardFilter_Owns(t *testing.T) {
+	for _, by := range []string{config.ShardByNamespace, config.ShardByUID} {
+		filters := make([]*shardFilter, 3)
+		for i := range filters {
+			filters[i] = &shardFilter{config.ShardConfig{Shard: i, TotalShards: 3, By: by, ClusterScopedShard: 1}}
+		}
+
+		counts := make([]int, len(filters))
+		for i := 0; i < 300; i++ {
+			obj := &metav1.ObjectMeta{Name: "pod", Namespace: fmt.Sprintf("namespace-%d", i), UID: k8stypes.UID(fmt.Sprintf("uid-%d", i))}
+			owners := 0
+			for shard, filter := range filters {
+				if filter.owns(obj) {
+					owners++
+					counts[shard]++
+				}
+			}
+			if owners != 1 {
+				t.Fatalf("Expected one shard to own %s by %s, got %d", obj.Namespace, by, owners)
+			}
+		}
+		for shard, count := range counts {
+			if count == 0 {
+				t.Errorf("Expected shard %d to own objects by %s, got none", shard, by)
+			}
+		}
+
+		node := &metav1.ObjectMeta{Name: "node-1", UID: "node-uid"}
+		for shard, filter := range filters {
+			if owns := filter.owns(node); owns != (shard == 1) {
+				t.Errorf("Expected cluster-scoped objects to belong to shard 1 only, got owns=%v for shard %d", owns, shard)
+			}
+		}
+	}
+}
+
+func TestShardFilter_Transform(t *testing.T) {
+	configMaps := []*corev1.ConfigMap{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "team-a", ResourceVersion: "1"}, Data: map[string]string{"key": "value"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "team-b", ResourceVersion: "2"}, Data: map[string]string{"key": "value"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "team-c", ResourceVersion: "3"}, Data: map[string]string{"key": "value"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "d", Namespace: "team-d", ResourceVersion: "4"}, Data: map[string]string{"key": "value"}},
+	}
+	filter := &shardFilter{config.ShardConfig{Shard: 0, TotalShards: 2, By: config.ShardByNamespace}}
+
+	client := fake.NewSimpleClientset()
+	expected := make(map[string]bool)
+	for _, configMap := range configMaps {
+		if _, err := client.CoreV1().ConfigMaps(configMap.Namespace).Create(context.Background(), configMap, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Failed to create configmap: %v", err)
+		}
+		if filter.owns(configMap) {
+			expected[configMap.Name] = true
+		}
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(client, 0, filter.informerFactoryOptions()...)
+	handler := resources.NewConfigMapHandler(client)
+	if err := handler.SetupInformer(factory, &recordingLogger{}, 0); err != nil {
+		t.Fatalf("Failed to setup informer: %v", err)
+	}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	// Objects of other shards are kept as metadata only
+	cached := handler.GetInformer().GetStore().List()
+	if len(cached) != len(configMaps) {
+		t.Fatalf("Expected %d cached objects, got %d", len(configMaps), len(cached))
+	}
+	for _, obj := range cached {
+		_, partial := obj.(*metav1.PartialObjectMetadata)
+		if name := obj.(metav1.Object).GetName(); partial == expected[name] {
+			t.Errorf("Expected %s to be cached in full=%v, got %T", name, expected[name], obj)
+		}
+	}
+
+	entries, err := handler.Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d", len(expected), len(entries))
+	}
+	for _, entry := range entries {
+		if name := entry.(types.ConfigMapData).Name; !expected[name] {
+			t.Errorf("Expected only entries of this shard, got %s", name)
+		}
+	}
+}
+
+func TestShardFilter_ServiceEndpointsByUID(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	ready := true
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("service-%d", i)
+		service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: k8stypes.UID("service-uid-" + name)}}
+		if _, err := client.CoreV1().Services("default").Create(context.Background(), service, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Failed to create service: %v", err)
+		}
+		// Each service has two slices, one per address family, with one ready endpoint each
+		for _, family := range []string{"ipv4", "ipv6"} {
+			slice := &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name + "-" + family,
+					Namespace: "default",
+					UID:       k8stypes.UID("slice-uid-" + name + "-" + family),
+					Labels:    map[string]string{discoveryv1.LabelServiceName: name},
+				},
+				Endpoints: []discoveryv1.Endpoint{{Addresses: []string{family}, Conditions: discoveryv1.EndpointConditions{Ready: &ready}}},
+			}
+			if _, err := client.DiscoveryV1().EndpointSlices("default").Create(context.Background(), slice, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("Failed to create endpoint slice: %v", err)
+			}
+		}
+	}
+
+	services := 0
+	for shard := 0; shard < 3; shard++ {
+		filter := &shardFilter{config.ShardConfig{Shard: shard, TotalShards: 3, By: config.ShardByUID}}
+		factory := informers.NewSharedInformerFactoryWithOptions(client, 0, filter.informerFactoryOptions()...)
+		handler := resources.NewServiceHandler(client)
+		if err := handler.SetupInformer(factory, &testutils.MockLogger{}, 0); err != nil {
+			t.Fatalf("Failed to setup informer: %v", err)
+		}
+		stopCh := make(chan struct{})
+		factory.Start(stopCh)
+		factory.WaitForCacheSync(stopCh)
+
+		entries, err := handler.Collect(context.Background(), nil)
+		close(stopCh)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, entry := range entries {
+			service := entry.(types.ServiceData)
+			if service.EndpointsCount != 2 {
+				t.Errorf("Expected 2 endpoints for %s on shard %d, got %d", service.Name, shard, service.EndpointsCount)
+			}
+		}
+		services += len(entries)
+	}
+	if services != 20 {
+		t.Errorf("Expected every service to be logged by one shard, got %d entries", services)
+	}
+}
@@ -1,18 +1,27 @@
 package testutils
 
+import "sync"
+
 type MockLogger struct {
+	mu   sync.Mutex
 	logs []any
 }
 
 func (m *MockLogger) Log(entry any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.logs = append(m.logs, entry)
 	return nil
 }
 
 func (m *MockLogger) GetLogs() []any {
-	return m.logs
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]any(nil), m.logs...)
 }
 
 func (m *MockLogger) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.logs = nil
 }
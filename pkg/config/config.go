@@ -2,9 +2,13 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/klog/v2"
 )
 
@@ -21,14 +25,179 @@ type CRDConfig struct {
 	CustomFields []string // e.g., ["spec.replicas", "spec.template.spec.containers"]
 }
 
+// GroupVersionResource returns the GVR the CRD configuration refers to
+func (c CRDConfig) GroupVersionResource() (schema.GroupVersionResource, error) {
+	gv, err := schema.ParseGroupVersion(c.APIVersion)
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("invalid apiVersion '%s' for CRD '%s': %w", c.APIVersion, c.Resource, err)
+	}
+	// ParseGroupVersion accepts an empty apiVersion and a missing version after the group
+	if gv.Version == "" {
+		return schema.GroupVersionResource{}, fmt.Errorf("invalid apiVersion '%s' for CRD '%s': missing version", c.APIVersion, c.Resource)
+	}
+	if c.Resource == "" {
+		return schema.GroupVersionResource{}, fmt.Errorf("missing resource for CRD apiVersion '%s'", c.APIVersion)
+	}
+	return gv.WithResource(c.Resource), nil
+}
+
+// Name returns the name used to reference the CRD in resource configs (e.g., "widgets.mygroup.example.com")
+func (c CRDConfig) Name() string {
+	gv, err := schema.ParseGroupVersion(c.APIVersion)
+	if err != nil || gv.Group == "" {
+		return c.Resource
+	}
+	return c.Resource + "." + gv.Group
+}
+
+// SinkConfig holds configuration for an output sink
+type SinkConfig struct {
+	Name      string            // e.g., "security"
+	Type      string            // e.g., "stdout", "file"
+	Options   map[string]string // Sink-specific options, e.g., {"path": "/var/log/kube-state-logs/rbac.log"}
+	Resources []string          // Resource types routed to this sink (empty receives every resource type not routed elsewhere)
+}
+
+// Option returns a sink option, or the default value if it is not set
+func (s SinkConfig) Option(key, defaultValue string) string {
+	if value, exists := s.Options[key]; exists && value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// IntOption returns a sink option parsed as an integer, or the default value if it is not set
+func (s SinkConfig) IntOption(key string, defaultValue int) (int, error) {
+	value := s.Option(key, "")
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s' for option '%s' of sink '%s': %w", value, key, s.Name, err)
+	}
+	return parsed, nil
+}
+
+// BoolOption returns a sink option parsed as a boolean, or the default value if it is not set
+func (s SinkConfig) BoolOption(key string, defaultValue bool) (bool, error) {
+	value := s.Option(key, "")
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value '%s' for option '%s' of sink '%s': %w", value, key, s.Name, err)
+	}
+	return parsed, nil
+}
+
+// DurationOption returns a sink option parsed as a duration, or the default value if it is not set
+func (s SinkConfig) DurationOption(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := s.Option(key, "")
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s' for option '%s' of sink '%s': %w", value, key, s.Name, err)
+	}
+	return parsed, nil
+}
+
+// FilterConfig holds the filters that entries must pass to be logged
+type FilterConfig struct {
+	ExcludeNamespaces []string // Namespaces whose entries are dropped
+	LabelSelector     string   // Selector that the labels of an entry's object must match, e.g. "app!=debug"
+}
+
+// LeaderElectionConfig holds the settings of leader election between replicas
+type LeaderElectionConfig struct {
+	Enabled       bool
+	LeaseName     string        // Name of the coordination.k8s.io Lease
+	Namespace     string        // Namespace of the Lease
+	Identity      string        // Holder identity of this replica, e.g. the pod name
+	LeaseDuration time.Duration // Time standby replicas wait before taking over an expired lease
+	RenewDeadline time.Duration // Time the leader keeps trying to renew before it stops leading
+	RetryPeriod   time.Duration // Interval between attempts to acquire or renew the lease
+}
+
+// Keys that objects can be sharded by
+const (
+	ShardByNamespace = "namespace"
+	ShardByUID       = "uid"
+)
+
+// ShardConfig holds the settings of sharding objects across replicas
+type ShardConfig struct {
+	Shard              int    // Shard of this replica, from 0 to TotalShards-1
+	TotalShards        int    // Number of shards; 0 or 1 disables sharding
+	By                 string // ShardByNamespace or ShardByUID
+	ClusterScopedShard int    // Shard that logs cluster-scoped objects
+}
+
+// Enabled reports whether objects are sharded across replicas
+func (s ShardConfig) Enabled() bool {
+	return s.TotalShards > 1
+}
+
+// Validate checks that the shard numbers are in range
+func (s ShardConfig) Validate() error {
+	if s.TotalShards < 0 {
+		return fmt.Errorf("total shards must not be negative, got %d", s.TotalShards)
+	}
+	if !s.Enabled() {
+		return nil
+	}
+	if s.Shard < 0 || s.Shard >= s.TotalShards {
+		return fmt.Errorf("shard must be between 0 and %d, got %d", s.TotalShards-1, s.Shard)
+	}
+	if s.ClusterScopedShard < 0 || s.ClusterScopedShard >= s.TotalShards {
+		return fmt.Errorf("cluster-scoped shard must be between 0 and %d, got %d", s.TotalShards-1, s.ClusterScopedShard)
+	}
+	if s.By != ShardByNamespace && s.By != ShardByUID {
+		return fmt.Errorf("invalid shard key '%s', expected '%s' or '%s'", s.By, ShardByNamespace, ShardByUID)
+	}
+	return nil
+}
+
+// ShardLeaseName returns the name of the Lease that the replicas of a shard contend for. Each
+// shard has a Lease of its own, suffixed with its number, so that every shard has a leader.
+func ShardLeaseName(leaseName string, sharding ShardConfig) string {
+	if !sharding.Enabled() {
+		return leaseName
+	}
+	return fmt.Sprintf("%s-shard-%d", leaseName, sharding.Shard)
+}
+
+// ParseOrdinal returns the StatefulSet ordinal at the end of a pod name, e.g. 2 for kube-state-logs-2
+func ParseOrdinal(podName string) (int, error) {
+	i := strings.LastIndex(podName, "-")
+	if i < 0 {
+		return 0, fmt.Errorf("pod name '%s' has no StatefulSet ordinal", podName)
+	}
+	ordinal, err := strconv.Atoi(podName[i+1:])
+	if err != nil || ordinal < 0 {
+		return 0, fmt.Errorf("pod name '%s' has no StatefulSet ordinal", podName)
+	}
+	return ordinal, nil
+}
+
 // Config holds the configuration for kube-state-logs
 type Config struct {
 	LogInterval     time.Duration
 	Resources       []string
 	ResourceConfigs []ResourceConfig // Individual resource configurations
 	CRDs            []CRDConfig      // CRD configurations
+	EventResources  []string         // Resources that also log entries on informer create/update/delete events
+	ChangeOnly      []string         // Resources that only log entries whose content changed since the last tick
+	HeartbeatTicks  int              // Log all entries of change-only resources every N ticks (0 disables)
+	Sinks           []SinkConfig     // Output sinks (defaults to stdout)
 	Namespaces      []string
+	Filters         FilterConfig // Filters applied to entries before they are logged
 	Kubeconfig      string
+	LeaderElection  LeaderElectionConfig
+	Sharding        ShardConfig
 }
 
 // ParseResourceList parses a comma-separated string into a slice of resource types
@@ -41,9 +210,9 @@ func ParseResourceList(resources string) []string {
 
 // ParseResourceConfigs parses a comma-separated string of resource:interval pairs
 // Format: "deployments:5m,pods:1m,services:2m"
-func ParseResourceConfigs(resourceConfigs string, defaultInterval time.Duration) []ResourceConfig {
+func ParseResourceConfigs(resourceConfigs string, defaultInterval time.Duration) ([]ResourceConfig, error) {
 	if resourceConfigs == "" {
-		return []ResourceConfig{}
+		return []ResourceConfig{}, nil
 	}
 
 	var configs []ResourceConfig
@@ -55,39 +224,40 @@ func ParseResourceConfigs(resourceConfigs string, defaultInterval time.Duration)
 			continue
 		}
 
-		parts := strings.Split(pair, ":")
-		if len(parts) == 1 {
-			// Just resource name, use default interval
-			configs = append(configs, ResourceConfig{
-				Name:     strings.TrimSpace(parts[0]),
-				Interval: defaultInterval,
-			})
-		} else if len(parts) == 2 {
-			// Resource name and interval
-			resourceName := strings.TrimSpace(parts[0])
-			intervalStr := strings.TrimSpace(parts[1])
-
-			interval, err := time.ParseDuration(intervalStr)
+		resourceName, intervalStr, hasInterval := strings.Cut(pair, ":")
+		resourceName = strings.TrimSpace(resourceName)
+		if resourceName == "" {
+			return nil, fmt.Errorf("missing resource name in '%s'", pair)
+		}
+
+		// Just resource name, use default interval
+		interval := defaultInterval
+		if hasInterval {
+			intervalStr = strings.TrimSpace(intervalStr)
+			parsed, err := time.ParseDuration(intervalStr)
 			if err != nil {
-				klog.Warningf("Invalid interval '%s' for resource '%s', using default: %v", intervalStr, resourceName, err)
-				interval = defaultInterval
+				return nil, fmt.Errorf("invalid interval '%s' for resource '%s': %w", intervalStr, resourceName, err)
 			}
-
-			configs = append(configs, ResourceConfig{
-				Name:     resourceName,
-				Interval: interval,
-			})
+			if parsed <= 0 {
+				return nil, fmt.Errorf("interval for resource '%s' must be positive, got %v", resourceName, parsed)
+			}
+			interval = parsed
 		}
+
+		configs = append(configs, ResourceConfig{
+			Name:     resourceName,
+			Interval: interval,
+		})
 	}
 
-	return configs
+	return configs, nil
 }
 
 // ParseCRDConfigs parses a comma-separated string of CRD configurations
-// Format: "apps/v1:deployments:spec.replicas,spec.template.spec.containers,networking.k8s.io/v1:ingresses:spec.rules"
-func ParseCRDConfigs(crdConfigs string) []CRDConfig {
+// Format: "apps/v1:deployments:spec.replicas|spec.template.spec.containers,networking.k8s.io/v1:ingresses:spec.rules"
+func ParseCRDConfigs(crdConfigs string) ([]CRDConfig, error) {
 	if crdConfigs == "" {
-		return []CRDConfig{}
+		return []CRDConfig{}, nil
 	}
 
 	var configs []CRDConfig
@@ -100,31 +270,92 @@ func ParseCRDConfigs(crdConfigs string) []CRDConfig {
 		}
 
 		parts := strings.Split(pair, ":")
-		if len(parts) >= 2 {
-			apiVersion := strings.TrimSpace(parts[0])
-			resource := strings.TrimSpace(parts[1])
-
-			var customFields []string
-			if len(parts) > 2 {
-				fieldsStr := strings.TrimSpace(parts[2])
-				if fieldsStr != "" {
-					customFields = strings.Split(fieldsStr, "|")
-					// Trim spaces from each field
-					for i, field := range customFields {
-						customFields[i] = strings.TrimSpace(field)
-					}
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid CRD config '%s': expected apiVersion:resource[:field|field...]", pair)
+		}
+
+		crdConfig := CRDConfig{
+			APIVersion: strings.TrimSpace(parts[0]),
+			Resource:   strings.TrimSpace(parts[1]),
+		}
+		if len(parts) > 2 {
+			fieldsStr := strings.TrimSpace(parts[2])
+			if fieldsStr != "" {
+				crdConfig.CustomFields = strings.Split(fieldsStr, "|")
+				// Trim spaces from each field
+				for i, field := range crdConfig.CustomFields {
+					crdConfig.CustomFields[i] = strings.TrimSpace(field)
 				}
 			}
+		}
+		if _, err := crdConfig.GroupVersionResource(); err != nil {
+			return nil, err
+		}
+
+		configs = append(configs, crdConfig)
+	}
+
+	return configs, nil
+}
 
-			configs = append(configs, CRDConfig{
-				APIVersion:   apiVersion,
-				Resource:     resource,
-				CustomFields: customFields,
-			})
+// ParseSinkConfigs parses a comma-separated string of sink specifications
+// Format: "[name=]type[?option=value&option=value]", where the "resources" option is a |-separated list
+// Example: "stdout,security=file?path=/var/log/rbac.log&resources=role|clusterrole"
+func ParseSinkConfigs(sinks string) ([]SinkConfig, error) {
+	if sinks == "" {
+		return []SinkConfig{}, nil
+	}
+
+	var configs []SinkConfig
+	names := make(map[string]bool)
+
+	for _, spec := range strings.Split(sinks, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		head, query, _ := strings.Cut(spec, "?")
+		name, sinkType, hasName := strings.Cut(head, "=")
+		if !hasName {
+			sinkType = name
 		}
+		name = strings.TrimSpace(name)
+		sinkType = strings.TrimSpace(sinkType)
+		if name == "" || sinkType == "" {
+			return nil, fmt.Errorf("invalid sink '%s': expected [name=]type[?options]", spec)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("duplicate sink name '%s'", name)
+		}
+		names[name] = true
+
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid options for sink '%s': %w", name, err)
+		}
+
+		sinkConfig := SinkConfig{
+			Name:    name,
+			Type:    sinkType,
+			Options: make(map[string]string),
+		}
+		for key := range values {
+			if key == "resources" {
+				for _, resource := range strings.Split(values.Get(key), "|") {
+					if resource = strings.TrimSpace(resource); resource != "" {
+						sinkConfig.Resources = append(sinkConfig.Resources, resource)
+					}
+				}
+				continue
+			}
+			sinkConfig.Options[key] = values.Get(key)
+		}
+
+		configs = append(configs, sinkConfig)
 	}
 
-	return configs
+	return configs, nil
 }
 
 // GetResourceInterval returns the interval for a specific resource
@@ -138,6 +369,16 @@ func (c *Config) GetResourceInterval(resourceName string) time.Duration {
 	return c.LogInterval
 }
 
+// IsEventDriven reports whether a resource should log entries on informer events
+func (c *Config) IsEventDriven(resourceName string) bool {
+	return slices.Contains(c.EventResources, resourceName)
+}
+
+// IsChangeOnly reports whether a resource should only log entries that changed since the last tick
+func (c *Config) IsChangeOnly(resourceName string) bool {
+	return slices.Contains(c.ChangeOnly, resourceName)
+}
+
 // ParseNamespaceList parses a comma-separated string into a slice of namespace names
 func ParseNamespaceList(namespaces string) []string {
 	if namespaces == "" {
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCRDConfig_GroupVersionResource(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        CRDConfig
+		expected      schema.GroupVersionResource
+		expectedError string
+	}{
+		{
+			name:     "group and version",
+			config:   CRDConfig{APIVersion: "cert-manager.io/v1", Resource: "certificates"},
+			expected: schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"},
+		},
+		{
+			name:     "core group",
+			config:   CRDConfig{APIVersion: "v1", Resource: "configmaps"},
+			expected: schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+		},
+		{
+			name:          "too many segments",
+			config:        CRDConfig{APIVersion: "cert-manager.io/v1/certificates", Resource: "certificates"},
+			expectedError: "invalid apiVersion 'cert-manager.io/v1/certificates'",
+		},
+		{
+			name:          "empty apiVersion",
+			config:        CRDConfig{Resource: "certificates"},
+			expectedError: "invalid apiVersion '' for CRD 'certificates': missing version",
+		},
+		{
+			name:          "missing version",
+			config:        CRDConfig{APIVersion: "cert-manager.io/", Resource: "certificates"},
+			expectedError: "missing version",
+		},
+		{
+			name:          "missing resource",
+			config:        CRDConfig{APIVersion: "cert-manager.io/v1"},
+			expectedError: "missing resource for CRD apiVersion 'cert-manager.io/v1'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gvr, err := tt.config.GroupVersionResource()
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("Expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if gvr != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, gvr)
+			}
+		})
+	}
+}
+
+func TestCRDConfig_Name(t *testing.T) {
+	tests := []struct {
+		config   CRDConfig
+		expected string
+	}{
+		{config: CRDConfig{APIVersion: "cert-manager.io/v1", Resource: "certificates"}, expected: "certificates.cert-manager.io"},
+		{config: CRDConfig{APIVersion: "v1", Resource: "configmaps"}, expected: "configmaps"},
+		{config: CRDConfig{APIVersion: "a/b/c", Resource: "widgets"}, expected: "widgets"},
+	}
+	for _, tt := range tests {
+		if name := tt.config.Name(); name != tt.expected {
+			t.Errorf("Expected name '%s' for %+v, got '%s'", tt.expected, tt.config, name)
+		}
+	}
+}
+
+func TestParseResourceConfigs(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expected      []ResourceConfig
+		expectedError string
+	}{
+		{name: "empty", input: "", expected: []ResourceConfig{}},
+		{
+			name:     "names and intervals",
+			input:    "deployments:5m, pods ,services:30s,",
+			expected: []ResourceConfig{{Name: "deployments", Interval: 5 * time.Minute}, {Name: "pods", Interval: time.Minute}, {Name: "services", Interval: 30 * time.Second}},
+		},
+		{name: "invalid interval", input: "pods:often", expectedError: "invalid interval 'often' for resource 'pods'"},
+		{name: "extra colon", input: "pods:1m:2m", expectedError: "invalid interval '1m:2m' for resource 'pods'"},
+		{name: "zero interval", input: "pods:0s", expectedError: "interval for resource 'pods' must be positive"},
+		{name: "missing name", input: ":5m", expectedError: "missing resource name in ':5m'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, err := ParseResourceConfigs(tt.input, time.Minute)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("Expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(configs, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, configs)
+			}
+		})
+	}
+}
+
+func TestParseCRDConfigs(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expected      []CRDConfig
+		expectedError string
+	}{
+		{name: "empty", input: "", expected: []CRDConfig{}},
+		{
+			name:  "with and without custom fields",
+			input: "mygroup.example.com/v1:widgets:spec.size | spec.color,cert-manager.io/v1:certificates",
+			expected: []CRDConfig{
+				{APIVersion: "mygroup.example.com/v1", Resource: "widgets", CustomFields: []string{"spec.size", "spec.color"}},
+				{APIVersion: "cert-manager.io/v1", Resource: "certificates"},
+			},
+		},
+		{name: "missing resource", input: "mygroup.example.com/v1", expectedError: "invalid CRD config 'mygroup.example.com/v1'"},
+		{name: "too many segments", input: "mygroup.example.com/v1:widgets:spec.size:spec.color", expectedError: "invalid CRD config"},
+		{name: "empty resource", input: "mygroup.example.com/v1:", expectedError: "missing resource for CRD apiVersion 'mygroup.example.com/v1'"},
+		{name: "missing version", input: "mygroup.example.com/:widgets", expectedError: "missing version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, err := ParseCRDConfigs(tt.input)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("Expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(configs, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, configs)
+			}
+		})
+	}
+}
+
+func TestShardLeaseName(t *testing.T) {
+	tests := []struct {
+		sharding ShardConfig
+		expected string
+	}{
+		{sharding: ShardConfig{}, expected: "kube-state-logs"},
+		{sharding: ShardConfig{Shard: 0, TotalShards: 1}, expected: "kube-state-logs"},
+		{sharding: ShardConfig{Shard: 0, TotalShards: 3}, expected: "kube-state-logs-shard-0"},
+		{sharding: ShardConfig{Shard: 2, TotalShards: 3}, expected: "kube-state-logs-shard-2"},
+	}
+	for _, tt := range tests {
+		if name := ShardLeaseName("kube-state-logs", tt.sharding); name != tt.expected {
+			t.Errorf("Expected lease name '%s' for %+v, got '%s'", tt.expected, tt.sharding, name)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// FileAPIVersion is the apiVersion of the config file format
+const FileAPIVersion = "kube-state-logs/v1"
+
+// Defaults applied to settings left out of a config file, matching the flag defaults
+const (
+	DefaultLogInterval    = time.Minute
+	DefaultHeartbeatTicks = 10
+)
+
+// File is the YAML config file, an alternative to the resource, namespace, CRD and sink flags.
+// Filters are only available in the config file.
+type File struct {
+	APIVersion     string          `json:"apiVersion"`
+	LogInterval    metav1.Duration `json:"logInterval,omitempty"`    // Default interval of resources and CRDs
+	HeartbeatTicks *int            `json:"heartbeatTicks,omitempty"` // Heartbeat of change-only resources
+	Namespaces     []string        `json:"namespaces,omitempty"`     // Namespaces to collect (empty for all)
+	Resources      []FileResource  `json:"resources,omitempty"`
+	CRDs           []FileCRD       `json:"crds,omitempty"`
+	Sinks          []FileSink      `json:"sinks,omitempty"` // Output sinks (defaults to stdout)
+	Filters        FileFilters     `json:"filters,omitempty"`
+}
+
+// FileFilters drops entries before they are routed to the sinks
+type FileFilters struct {
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"` // Namespaces whose entries are dropped
+	LabelSelector     string   `json:"labelSelector,omitempty"`     // e.g., "app.kubernetes.io/managed-by!=helm"
+}
+
+// FileResource configures a built-in resource type
+type FileResource struct {
+	Name        string          `json:"name"`                  // e.g., "pod"
+	Interval    metav1.Duration `json:"interval,omitempty"`    // Overrides logInterval
+	EventDriven bool            `json:"eventDriven,omitempty"` // Also log entries on create, update and delete
+	ChangeOnly  bool            `json:"changeOnly,omitempty"`  // Only log entries that changed since the last tick
+}
+
+// FileCRD configures a custom resource
+type FileCRD struct {
+	APIVersion   string          `json:"apiVersion"` // e.g., "mygroup.example.com/v1"
+	Resource     string          `json:"resource"`   // e.g., "widgets"
+	CustomFields []string        `json:"customFields,omitempty"`
+	Interval     metav1.Duration `json:"interval,omitempty"`
+	EventDriven  bool            `json:"eventDriven,omitempty"`
+	ChangeOnly   bool            `json:"changeOnly,omitempty"`
+}
+
+// FileSink configures an output sink. Options take the same keys as the query string of a
+// --sinks entry; scalar values such as numbers and booleans are converted to strings.
+type FileSink struct {
+	Name      string         `json:"name,omitempty"` // Defaults to the type
+	Type      string         `json:"type"`
+	Resources []string       `json:"resources,omitempty"`
+	Options   map[string]any `json:"options,omitempty"`
+}
+
+// LoadFile reads and validates a config file
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	cfg, err := ParseFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseFile parses and validates the content of a config file. Unknown fields are errors,
+// and all validation errors are returned together.
+func ParseFile(data []byte) (*Config, error) {
+	var file File
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, err
+	}
+	if err := file.Validate(); err != nil {
+		return nil, err
+	}
+	return file.Config(), nil
+}
+
+// Validate checks a config file, returning an error per invalid setting
+func (f *File) Validate() error {
+	var errs []error
+	if f.APIVersion != FileAPIVersion {
+		errs = append(errs, fmt.Errorf("apiVersion: expected '%s', got '%s'", FileAPIVersion, f.APIVersion))
+	}
+	if f.LogInterval.Duration < 0 {
+		errs = append(errs, fmt.Errorf("logInterval: must not be negative, got %v", f.LogInterval.Duration))
+	}
+	if f.HeartbeatTicks != nil && *f.HeartbeatTicks < 0 {
+		errs = append(errs, fmt.Errorf("heartbeatTicks: must not be negative, got %d", *f.HeartbeatTicks))
+	}
+	for i, namespace := range f.Namespaces {
+		if namespace == "" {
+			errs = append(errs, fmt.Errorf("namespaces[%d]: must not be empty", i))
+		}
+	}
+	for i, namespace := range f.Filters.ExcludeNamespaces {
+		if namespace == "" {
+			errs = append(errs, fmt.Errorf("filters.excludeNamespaces[%d]: must not be empty", i))
+		}
+	}
+	if _, err := labels.Parse(f.Filters.LabelSelector); err != nil {
+		errs = append(errs, fmt.Errorf("filters.labelSelector: %w", err))
+	}
+	if len(f.Resources) == 0 && len(f.CRDs) == 0 {
+		errs = append(errs, errors.New("resources: at least one resource or CRD is required"))
+	}
+
+	names := make(map[string]string)
+	for i, resource := range f.Resources {
+		path := fmt.Sprintf("resources[%d]", i)
+		if resource.Name == "" {
+			errs = append(errs, fmt.Errorf("%s.name: is required", path))
+		} else if previous, exists := names[resource.Name]; exists {
+			errs = append(errs, fmt.Errorf("%s.name: '%s' is already configured by %s", path, resource.Name, previous))
+		} else {
+			names[resource.Name] = path
+		}
+		if resource.Interval.Duration < 0 {
+			errs = append(errs, fmt.Errorf("%s.interval: must not be negative, got %v", path, resource.Interval.Duration))
+		}
+	}
+
+	for i, crd := range f.CRDs {
+		path := fmt.Sprintf("crds[%d]", i)
+		crdConfig := CRDConfig{APIVersion: crd.APIVersion, Resource: crd.Resource}
+		if _, err := crdConfig.GroupVersionResource(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		} else if previous, exists := names[crdConfig.Name()]; exists {
+			errs = append(errs, fmt.Errorf("%s: '%s' is already configured by %s", path, crdConfig.Name(), previous))
+		} else {
+			names[crdConfig.Name()] = path
+		}
+		if crd.Interval.Duration < 0 {
+			errs = append(errs, fmt.Errorf("%s.interval: must not be negative, got %v", path, crd.Interval.Duration))
+		}
+	}
+
+	sinkNames := make(map[string]string)
+	for i, sink := range f.Sinks {
+		path := fmt.Sprintf("sinks[%d]", i)
+		if sink.Type == "" {
+			errs = append(errs, fmt.Errorf("%s.type: is required", path))
+		}
+		name := sink.name()
+		if previous, exists := sinkNames[name]; exists && name != "" {
+			errs = append(errs, fmt.Errorf("%s.name: '%s' is already used by %s", path, name, previous))
+		}
+		sinkNames[name] = path
+		for key, value := range sink.Options {
+			if _, err := optionString(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s.options.%s: %w", path, key, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Config converts a validated config file into a Config
+func (f *File) Config() *Config {
+	cfg := &Config{
+		LogInterval:    f.LogInterval.Duration,
+		HeartbeatTicks: DefaultHeartbeatTicks,
+		Namespaces:     append([]string{}, f.Namespaces...),
+		Resources:      []string{},
+		Sinks:          []SinkConfig{},
+		Filters: FilterConfig{
+			ExcludeNamespaces: f.Filters.ExcludeNamespaces,
+			LabelSelector:     f.Filters.LabelSelector,
+		},
+	}
+	if cfg.LogInterval == 0 {
+		cfg.LogInterval = DefaultLogInterval
+	}
+	if f.HeartbeatTicks != nil {
+		cfg.HeartbeatTicks = *f.HeartbeatTicks
+	}
+
+	addResource := func(name string, interval time.Duration, eventDriven, changeOnly bool) {
+		if interval == 0 {
+			interval = cfg.LogInterval
+		}
+		cfg.ResourceConfigs = append(cfg.ResourceConfigs, ResourceConfig{Name: name, Interval: interval})
+		if eventDriven {
+			cfg.EventResources = append(cfg.EventResources, name)
+		}
+		if changeOnly {
+			cfg.ChangeOnly = append(cfg.ChangeOnly, name)
+		}
+	}
+
+	for _, resource := range f.Resources {
+		cfg.Resources = append(cfg.Resources, resource.Name)
+		addResource(resource.Name, resource.Interval.Duration, resource.EventDriven, resource.ChangeOnly)
+	}
+	for _, crd := range f.CRDs {
+		crdConfig := CRDConfig{APIVersion: crd.APIVersion, Resource: crd.Resource, CustomFields: crd.CustomFields}
+		cfg.CRDs = append(cfg.CRDs, crdConfig)
+		addResource(crdConfig.Name(), crd.Interval.Duration, crd.EventDriven, crd.ChangeOnly)
+	}
+
+	for _, sink := range f.Sinks {
+		sinkConfig := SinkConfig{
+			Name:      sink.name(),
+			Type:      sink.Type,
+			Options:   make(map[string]string),
+			Resources: sink.Resources,
+		}
+		for key, value := range sink.Options {
+			sinkConfig.Options[key], _ = optionString(value)
+		}
+		cfg.Sinks = append(cfg.Sinks, sinkConfig)
+	}
+
+	return cfg
+}
+
+// name returns the name of a sink, which defaults to its type as with --sinks
+func (s FileSink) name() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Type
+}
+
+// optionString converts a scalar sink option value to the string form used by --sinks
+func optionString(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("must be a string, number or boolean, got %T", value)
+	}
+}
+
+// WatchFile applies a config file again when its content changes, checked every interval,
+// or when a signal is received on reload. A file that fails to load or apply is logged and
+// the current configuration stays in effect until the file changes again.
+func WatchFile(ctx context.Context, path string, interval time.Duration, reload <-chan os.Signal, apply func(*Config) error) {
+	last, err := os.ReadFile(path)
+	if err != nil {
+		klog.Errorf("Failed to read config file %s: %v", path, err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		forced := false
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case sig := <-reload:
+			klog.Infof("Received signal %v, reloading %s", sig, path)
+			forced = true
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			klog.Errorf("Failed to read config file %s: %v", path, err)
+			continue
+		}
+		if !forced && bytes.Equal(data, last) {
+			continue
+		}
+		last = data
+
+		cfg, err := ParseFile(data)
+		if err != nil {
+			klog.Errorf("Invalid config file %s, keeping the current configuration: %v", path, err)
+			continue
+		}
+		if err := apply(cfg); err != nil {
+			klog.Errorf("Failed to apply config file %s, keeping the current configuration: %v", path, err)
+			continue
+		}
+		klog.Infof("Applied config file %s", path)
+	}
+}
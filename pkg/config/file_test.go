@@ -0,0 +1,204 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConfigFile = `
+apiVersion: kube-state-logs/v1
+logInterval: 2m
+namespaces: [default, payments]
+resources:
+  - name: pod
+    interval: 30s
+    eventDriven: true
+  - name: configmap
+    changeOnly: true
+crds:
+  - apiVersion: cert-manager.io/v1
+    resource: certificates
+    interval: 5m
+    customFields: [spec.dnsNames, status.notAfter]
+sinks:
+  - type: stdout
+  - name: security
+    type: rotating-file
+    resources: [role, clusterrole]
+    options:
+      path: /var/log/kube-state-logs/rbac.log
+      max-size-mb: 50
+      compress: true
+filters:
+  excludeNamespaces: [kube-system]
+  labelSelector: app.kubernetes.io/managed-by!=helm
+`
+
+func TestParseFile(t *testing.T) {
+	cfg, err := ParseFile([]byte(testConfigFile))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := &Config{
+		LogInterval: 2 * time.Minute,
+		Resources:   []string{"pod", "configmap"},
+		ResourceConfigs: []ResourceConfig{
+			{Name: "pod", Interval: 30 * time.Second},
+			{Name: "configmap", Interval: 2 * time.Minute},
+			{Name: "certificates.cert-manager.io", Interval: 5 * time.Minute},
+		},
+		CRDs:           []CRDConfig{{APIVersion: "cert-manager.io/v1", Resource: "certificates", CustomFields: []string{"spec.dnsNames", "status.notAfter"}}},
+		EventResources: []string{"pod"},
+		ChangeOnly:     []string{"configmap"},
+		HeartbeatTicks: DefaultHeartbeatTicks,
+		Sinks: []SinkConfig{
+			{Name: "stdout", Type: "stdout", Options: map[string]string{}},
+			{
+				Name:      "security",
+				Type:      "rotating-file",
+				Options:   map[string]string{"path": "/var/log/kube-state-logs/rbac.log", "max-size-mb": "50", "compress": "true"},
+				Resources: []string{"role", "clusterrole"},
+			},
+		},
+		Namespaces: []string{"default", "payments"},
+		Filters:    FilterConfig{ExcludeNamespaces: []string{"kube-system"}, LabelSelector: "app.kubernetes.io/managed-by!=helm"},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, cfg)
+	}
+}
+
+func TestParseFile_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "missing apiVersion",
+			content:  "resources: [{name: pod}]",
+			expected: []string{"apiVersion: expected 'kube-state-logs/v1', got ''"},
+		},
+		{
+			name:     "unknown field",
+			content:  "apiVersion: kube-state-logs/v1\nresources: [{name: pod, intervall: 1m}]",
+			expected: []string{`unknown field "intervall"`},
+		},
+		{
+			name:     "invalid duration",
+			content:  "apiVersion: kube-state-logs/v1\nresources: [{name: pod, interval: often}]",
+			expected: []string{"often"},
+		},
+		{
+			name:     "no resources",
+			content:  "apiVersion: kube-state-logs/v1",
+			expected: []string{"at least one resource or CRD is required"},
+		},
+		{
+			name: "several errors",
+			content: `apiVersion: kube-state-logs/v1
+resources:
+  - name: pod
+  - name: pod
+  - interval: -1m
+crds:
+  - apiVersion: cert-manager.io/v1/certificates
+    resource: certificates
+sinks:
+  - name: out
+    type: stdout
+  - name: out
+    type: file
+    options:
+      path: [a, b]
+filters:
+  excludeNamespaces: [""]
+  labelSelector: "app in (a"
+`,
+			expected: []string{
+				"resources[1].name: 'pod' is already configured by resources[0]",
+				"resources[2].name: is required",
+				"resources[2].interval: must not be negative",
+				"crds[0]: invalid apiVersion 'cert-manager.io/v1/certificates'",
+				"sinks[1].name: 'out' is already used by sinks[0]",
+				"sinks[1].options.path: must be a string, number or boolean",
+				"filters.excludeNamespaces[0]: must not be empty",
+				"filters.labelSelector: ",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFile([]byte(tt.content))
+			if err == nil {
+				t.Fatalf("Expected an error")
+			}
+			for _, message := range tt.expected {
+				if !strings.Contains(err.Error(), message) {
+					t.Errorf("Expected error to contain '%s', got '%v'", message, err)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(testConfigFile), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	applied := make(chan *Config, 10)
+	reload := make(chan os.Signal, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go WatchFile(ctx, path, 10*time.Millisecond, reload, func(cfg *Config) error {
+		applied <- cfg
+		return nil
+	})
+
+	// Unchanged files are not applied again
+	select {
+	case cfg := <-applied:
+		t.Fatalf("Expected no reload of an unchanged file, got %+v", cfg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Invalid files are not applied
+	if err := os.WriteFile(path, []byte("apiVersion: kube-state-logs/v2"), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	select {
+	case cfg := <-applied:
+		t.Fatalf("Expected an invalid file not to be applied, got %+v", cfg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	updated := strings.Replace(testConfigFile, "logInterval: 2m", "logInterval: 3m", 1)
+	if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	select {
+	case cfg := <-applied:
+		if cfg.LogInterval != 3*time.Minute {
+			t.Errorf("Expected log interval 3m, got %v", cfg.LogInterval)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the changed file to be applied")
+	}
+
+	// A signal reloads the file even if it did not change
+	reload <- os.Interrupt
+	select {
+	case <-applied:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the file to be applied on signal")
+	}
+}
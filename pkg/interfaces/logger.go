@@ -12,8 +12,32 @@ type Logger interface {
 	Log(entry any) error
 }
 
+// ContextLogger is implemented by loggers that can give up on an entry once ctx is done,
+// e.g. while waiting for room in a full queue
+type ContextLogger interface {
+	LogContext(ctx context.Context, entry any) error
+}
+
+// ResourceCollector defines the contract for anything that can produce log entries from an informer cache
+type ResourceCollector interface {
+	Collect(ctx context.Context, namespaces []string) ([]any, error)
+}
+
 // ResourceHandler defines the interface for resource-specific collectors
 type ResourceHandler interface {
+	ResourceCollector
 	SetupInformer(factory informers.SharedInformerFactory, logger Logger, resyncPeriod time.Duration) error
-	Collect(ctx context.Context, namespaces []string) ([]any, error)
+}
+
+// EventDrivenHandler is implemented by handlers that can log entries directly from informer events
+type EventDrivenHandler interface {
+	EnableEventLogging(namespaces []string) error
+	EnableDeletionLogging(namespaces []string) error
+}
+
+// DeduplicatingCollector is implemented by collectors that log each object only once. The
+// objects in the cache are marked as seen without being logged when the informers sync and
+// while the replica stands by, so that restarts, reloads and takeovers do not log them again.
+type DeduplicatingCollector interface {
+	MarkSeen(namespaces []string)
 }
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// funcGaugeVec is a gauge vector whose values are read from functions when scraped, such as
+// the queue lengths of sinks that come and go when sinks are reloaded
+type funcGaugeVec struct {
+	desc  *prometheus.Desc
+	mu    sync.Mutex
+	funcs map[string]func() float64 // Keyed by the value of the single label
+}
+
+// newFuncGaugeVec creates a funcGaugeVec with a single variable label
+func newFuncGaugeVec(desc *prometheus.Desc) *funcGaugeVec {
+	return &funcGaugeVec{desc: desc, funcs: make(map[string]func() float64)}
+}
+
+// SetFunc makes the gauge with the given label value report the result of fn when scraped
+func (g *funcGaugeVec) SetFunc(fn func() float64, labelValue string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.funcs[labelValue] = fn
+}
+
+// Delete removes the gauge with the given label value
+func (g *funcGaugeVec) Delete(labelValue string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.funcs, labelValue)
+}
+
+// Describe implements prometheus.Collector
+func (g *funcGaugeVec) Describe(ch chan<- *prometheus.Desc) {
+	ch <- g.desc
+}
+
+// Collect implements prometheus.Collector
+func (g *funcGaugeVec) Collect(ch chan<- prometheus.Metric) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for labelValue, fn := range g.funcs {
+		ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, fn(), labelValue)
+	}
+}
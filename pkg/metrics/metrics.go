@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/klog/v2"
+)
+
+// Registry holds the metrics of kube-state-logs itself, along with Go runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+// Bucket upper bounds in seconds
+var (
+	collectionBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+	writeBuckets      = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+)
+
+// Leader is 1 while this replica logs entries, and 0 while it stands by under leader election
+var Leader = factory.NewGauge(prometheus.GaugeOpts{
+	Name: "kube_state_logs_leader",
+	Help: "Whether this replica is leading and logs entries (1) or is standing by (0).",
+})
+
+// Collection metrics, labeled by resource type
+var (
+	CollectionDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kube_state_logs_collection_duration_seconds",
+		Help:    "Time taken to collect and log the entries of a resource type on a tick.",
+		Buckets: collectionBuckets,
+	}, []string{"resource"})
+	CollectionErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kube_state_logs_collection_errors_total",
+		Help: "Number of ticks on which collecting a resource type failed.",
+	}, []string{"resource"})
+	LastSuccessfulCollection = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kube_state_logs_last_successful_collection_timestamp_seconds",
+		Help: "Unix time of the last successful collection of a resource type.",
+	}, []string{"resource"})
+	InformerCacheObjects = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kube_state_logs_informer_cache_objects",
+		Help: "Number of objects in the informer cache of a resource type at the last collection.",
+	}, []string{"resource"})
+	EntriesEmitted = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kube_state_logs_entries_emitted_total",
+		Help: "Number of entries handed to the sinks, from ticks and informer events.",
+	}, []string{"resource"})
+)
+
+// Sink metrics, labeled by sink name
+var (
+	SinkWriteDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kube_state_logs_sink_write_duration_seconds",
+		Help:    "Time taken by a sink to write a batch of entries.",
+		Buckets: writeBuckets,
+	}, []string{"sink"})
+	SinkWriteErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kube_state_logs_sink_write_errors_total",
+		Help: "Number of batches a sink failed to write.",
+	}, []string{"sink"})
+	SinkQueueLength = newFuncGaugeVec(prometheus.NewDesc("kube_state_logs_sink_queue_length",
+		"Number of entries waiting in the queue of a sink.", []string{"sink"}, nil))
+	SinkDroppedEntries = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kube_state_logs_sink_dropped_entries_total",
+		Help: "Number of entries a sink discarded because its queue was full.",
+	}, []string{"sink"})
+	SpoolDroppedEntries = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kube_state_logs_spool_dropped_entries_total",
+		Help: "Number of spooled entries a sink discarded because its spool was full or the entries were rejected on replay.",
+	}, []string{"sink"})
+)
+
+func init() {
+	Registry.MustRegister(
+		SinkQueueLength,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler returns the HTTP handler that serves the metrics in the Prometheus formats
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Serve serves the metrics on /metrics at the given address until ctx is done
+func Serve(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			klog.Errorf("Failed to shut down metrics server: %v", err)
+		}
+	}()
+
+	klog.Infof("Serving metrics on %s/metrics", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
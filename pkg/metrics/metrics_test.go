@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	EntriesEmitted.WithLabelValues("pod").Add(3)
+	CollectionDuration.WithLabelValues("pod").Observe(0.5)
+	Leader.Set(1)
+	SinkQueueLength.SetFunc(func() float64 { return 7 }, "loki")
+	SinkQueueLength.SetFunc(func() float64 { return 1 }, "removed")
+	SinkQueueLength.Delete("removed")
+	SpoolDroppedEntries.WithLabelValues("loki").Inc()
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("Expected the Prometheus text format, got '%s'", contentType)
+	}
+
+	body := recorder.Body.String()
+	for _, expected := range []string{
+		"# TYPE kube_state_logs_entries_emitted_total counter\n",
+		`kube_state_logs_entries_emitted_total{resource="pod"} 3` + "\n",
+		`kube_state_logs_collection_duration_seconds_bucket{resource="pod",le="0.5"} 1` + "\n",
+		"kube_state_logs_leader 1\n",
+		"# TYPE kube_state_logs_sink_queue_length gauge\n",
+		`kube_state_logs_sink_queue_length{sink="loki"} 7` + "\n",
+		`kube_state_logs_spool_dropped_entries_total{sink="loki"} 1` + "\n",
+		"go_goroutines ",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected the metrics to contain '%s', got %s", strings.TrimSpace(expected), body)
+		}
+	}
+	if strings.Contains(body, `sink="removed"`) {
+		t.Errorf("Expected deleted gauges to be gone, got %s", body)
+	}
+}
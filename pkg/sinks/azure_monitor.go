@@ -0,0 +1,303 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"k8s.io/klog/v2"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+const (
+	azureMonitorAPIVersion      = "2023-01-01"
+	azureMonitorMaxRequestBytes = 1000000 // Logs Ingestion API limit for a single call
+	azureMonitorStreamPrefix    = "Custom-KubeStateLogs"
+	azureMonitorScope           = "https://monitor.azure.com/.default"
+)
+
+// AzureMonitorOptions configures an AzureMonitorSink
+type AzureMonitorOptions struct {
+	Endpoint        string            // Data collection endpoint, e.g. "https://my-dce.eastus-1.ingest.monitor.azure.com"
+	RuleID          string            // Immutable ID of the data collection rule, e.g. "dcr-00000000000000000000000000000000"
+	StreamPrefix    string            // Stream name prefix, followed by the resource type in PascalCase
+	Streams         map[string]string // Stream per resource type, overriding the prefix
+	MaxRequestBytes int               // Maximum size of a request body
+	Credential      azcore.TokenCredential
+	Scope           string        // Scope of the access tokens
+	Timeout         time.Duration // Timeout for a single request
+	Retry           RetryOptions
+}
+
+// AzureMonitorSink sends entries to Azure Monitor with the Logs Ingestion API. Entries are
+// sent to one DCR stream per resource type, so each resource type can land in its own table.
+type AzureMonitorSink struct {
+	opts   AzureMonitorOptions
+	base   *url.URL
+	client *http.Client
+}
+
+// NewAzureMonitorSink creates an AzureMonitorSink
+func NewAzureMonitorSink(opts AzureMonitorOptions) (*AzureMonitorSink, error) {
+	if opts.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+	if opts.RuleID == "" {
+		return nil, fmt.Errorf("dcr-id is required")
+	}
+	if opts.Credential == nil {
+		return nil, fmt.Errorf("credential is required")
+	}
+	if opts.StreamPrefix == "" {
+		opts.StreamPrefix = azureMonitorStreamPrefix
+	}
+	if opts.Scope == "" {
+		opts.Scope = azureMonitorScope
+	}
+	if opts.MaxRequestBytes <= 0 || opts.MaxRequestBytes > azureMonitorMaxRequestBytes {
+		opts.MaxRequestBytes = azureMonitorMaxRequestBytes
+	}
+
+	base, err := url.Parse(opts.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint '%s': %w", opts.Endpoint, err)
+	}
+	base.Path = strings.TrimSuffix(base.Path, "/")
+
+	return &AzureMonitorSink{
+		opts:   opts,
+		base:   base,
+		client: &http.Client{Timeout: opts.Timeout},
+	}, nil
+}
+
+// newAzureMonitorSink creates an AzureMonitorSink from a sink's endpoint, dcr-id,
+// stream-prefix, streams, max-request-bytes, workload identity, timeout and retry options
+func newAzureMonitorSink(cfg config.SinkConfig) (*AzureMonitorSink, error) {
+	streams, err := parsePairs(cfg.Option("streams", ""))
+	if err != nil {
+		return nil, err
+	}
+	maxRequestBytes, err := cfg.IntOption("max-request-bytes", azureMonitorMaxRequestBytes)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := cfg.DurationOption("timeout", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	retry, err := RetryOptionsFromConfig(cfg, DefaultRetryOptions())
+	if err != nil {
+		return nil, err
+	}
+	// Settings that are not given fall back to the AZURE_* environment variables
+	credentialOptions := &azidentity.WorkloadIdentityCredentialOptions{
+		TenantID:      cfg.Option("tenant-id", ""),
+		ClientID:      cfg.Option("client-id", ""),
+		TokenFilePath: cfg.Option("token-file", ""),
+	}
+	if authorityHost := cfg.Option("authority-host", ""); authorityHost != "" {
+		credentialOptions.Cloud.ActiveDirectoryAuthorityHost = authorityHost
+	}
+	credential, err := azidentity.NewWorkloadIdentityCredential(credentialOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workload identity credential: %w", err)
+	}
+
+	return NewAzureMonitorSink(AzureMonitorOptions{
+		Endpoint:        cfg.Option("endpoint", ""),
+		RuleID:          cfg.Option("dcr-id", ""),
+		StreamPrefix:    cfg.Option("stream-prefix", azureMonitorStreamPrefix),
+		Streams:         streams,
+		MaxRequestBytes: maxRequestBytes,
+		Credential:      credential,
+		Scope:           cfg.Option("scope", azureMonitorScope),
+		Timeout:         timeout,
+		Retry:           retry,
+	})
+}
+
+// azureMonitorRecord is a serialized entry
+type azureMonitorRecord struct {
+	entry any
+	data  []byte
+}
+
+// azureMonitorRequest is a JSON array of records sent to a stream in one request
+type azureMonitorRequest struct {
+	stream  string
+	body    []byte
+	entries []any
+}
+
+// WriteBatch sends entries to their streams, split into requests under the size limit.
+// Retries resume with the request that failed, and a failed batch only reports the entries
+// that were not sent. Entries larger than the limit on their own are logged and dropped.
+func (s *AzureMonitorSink) WriteBatch(ctx context.Context, entries []any) error {
+	var streams []string
+	records := make(map[string][]azureMonitorRecord)
+	now := time.Now()
+
+	for _, entry := range entries {
+		metadata, _ := types.MetadataOf(entry)
+		record, err := s.record(entry, metadata, now)
+		if err != nil {
+			return err
+		}
+		if len(record)+2 > s.opts.MaxRequestBytes {
+			klog.Errorf("Dropping %s entry %s/%s of %d bytes, larger than the Logs Ingestion API limit", metadata.ResourceType, metadata.Namespace, metadata.Name, len(record))
+			continue
+		}
+
+		stream := s.stream(metadata.ResourceType)
+		if _, exists := records[stream]; !exists {
+			streams = append(streams, stream)
+		}
+		records[stream] = append(records[stream], azureMonitorRecord{entry: entry, data: record})
+	}
+
+	var requests []azureMonitorRequest
+	for _, stream := range streams {
+		requests = append(requests, s.split(stream, records[stream])...)
+	}
+
+	sent := 0
+	err := s.opts.Retry.Do(ctx, func() error {
+		for sent < len(requests) {
+			request := requests[sent]
+			if err := s.upload(ctx, request.stream, request.body); err != nil {
+				return fmt.Errorf("failed to upload to stream '%s': %w", request.stream, err)
+			}
+			sent++
+		}
+		return nil
+	})
+	if err != nil && sent > 0 {
+		var unsent []any
+		for _, request := range requests[sent:] {
+			unsent = append(unsent, request.entries...)
+		}
+		return partialWrite(err, unsent)
+	}
+	return err
+}
+
+// record serializes an entry with the TimeGenerated column required by Log Analytics tables
+func (s *AzureMonitorSink) record(entry any, metadata types.LogEntryMetadata, now time.Time) ([]byte, error) {
+	fields, err := entryFields(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := metadata.Timestamp
+	if timestamp.IsZero() {
+		timestamp = now
+	}
+	fields["TimeGenerated"] = timestamp.UTC().Format(time.RFC3339Nano)
+
+	record, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal entry of type %T: %w", entry, err)
+	}
+	return record, nil
+}
+
+// stream returns the DCR stream for a resource type
+func (s *AzureMonitorSink) stream(resourceType string) string {
+	if stream, exists := s.opts.Streams[resourceType]; exists {
+		return stream
+	}
+	return s.opts.StreamPrefix + pascalCase(resourceType)
+}
+
+// split joins the records of a stream into requests that each fit in the size limit
+func (s *AzureMonitorSink) split(stream string, records []azureMonitorRecord) []azureMonitorRequest {
+	var requests []azureMonitorRequest
+	var body bytes.Buffer
+	var entries []any
+
+	for _, record := range records {
+		if body.Len() > 0 && body.Len()+1+len(record.data)+1 > s.opts.MaxRequestBytes {
+			body.WriteByte(']')
+			requests = append(requests, azureMonitorRequest{stream: stream, body: bytes.Clone(body.Bytes()), entries: entries})
+			body.Reset()
+			entries = nil
+		}
+		if body.Len() == 0 {
+			body.WriteByte('[')
+		} else {
+			body.WriteByte(',')
+		}
+		body.Write(record.data)
+		entries = append(entries, record.entry)
+	}
+	if body.Len() > 0 {
+		body.WriteByte(']')
+		requests = append(requests, azureMonitorRequest{stream: stream, body: body.Bytes(), entries: entries})
+	}
+
+	return requests
+}
+
+// upload sends a JSON array of records to a stream
+func (s *AzureMonitorSink) upload(ctx context.Context, stream string, body []byte) error {
+	// The credential caches tokens until shortly before they expire
+	token, err := s.opts.Credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{s.opts.Scope}})
+	if err != nil {
+		// Microsoft Entra ID rejected the identity, which a retry does not change
+		var authErr *azidentity.AuthenticationFailedError
+		if errors.As(err, &authErr) {
+			return fmt.Errorf("failed to get access token: %w", err)
+		}
+		return retryable(fmt.Errorf("failed to get access token: %w", err), 0)
+	}
+
+	target := *s.base
+	target.Path += "/dataCollectionRules/" + url.PathEscape(s.opts.RuleID) + "/streams/" + url.PathEscape(stream)
+	target.RawQuery = url.Values{"api-version": {azureMonitorAPIVersion}}.Encode()
+
+	req, err := http.NewRequest(http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return retryable(fmt.Errorf("failed to send logs: %w", err), 0)
+	}
+	defer resp.Body.Close()
+
+	return checkHTTPResponse(resp)
+}
+
+// pascalCase converts a resource type such as "init_container" to "InitContainer"
+func pascalCase(value string) string {
+	var result strings.Builder
+	upper := true
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		result.WriteRune(r)
+	}
+	return result.String()
+}
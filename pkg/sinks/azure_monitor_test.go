@@ -0,0 +1,189 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// countingCredential hands out numbered access tokens
+type countingCredential struct {
+	mu     sync.Mutex
+	scopes []string
+}
+
+func (c *countingCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scopes = append(c.scopes, options.Scopes...)
+	return azcore.AccessToken{Token: fmt.Sprintf("access-token-%d", len(c.scopes)), ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestAzureMonitorSink_WriteBatch(t *testing.T) {
+	var mu sync.Mutex
+	uploads := map[string][][]map[string]any{}
+	var authorizations []string
+	failures := 0
+
+	// Stand-in for the data collection endpoint
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		prefix := "/dataCollectionRules/dcr-test/streams/"
+		if !strings.HasPrefix(r.URL.Path, prefix) || r.URL.Query().Get("api-version") != azureMonitorAPIVersion {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if len(authorizations) == 2 && failures == 0 {
+			// The second request is throttled once
+			failures++
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if len(body) > 600 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		var records []map[string]any
+		if err := json.Unmarshal(body, &records); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		stream := strings.TrimPrefix(r.URL.Path, prefix)
+		uploads[stream] = append(uploads[stream], records)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	credential := &countingCredential{}
+	sink, err := NewAzureMonitorSink(AzureMonitorOptions{
+		Endpoint:        server.URL,
+		RuleID:          "dcr-test",
+		StreamPrefix:    azureMonitorStreamPrefix,
+		Streams:         map[string]string{"clusterrole": "Custom-Rbac"},
+		MaxRequestBytes: 600,
+		Credential:      credential,
+		Timeout:         5 * time.Second,
+		Retry:           RetryOptions{MaxRetries: 2, InitialBackoff: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	timestamp := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var entries []any
+	for i := range 4 {
+		entries = append(entries, &types.ContainerData{Timestamp: timestamp, ResourceType: "init_container", Name: fmt.Sprintf("init-%d", i)})
+	}
+	entries = append(entries, &types.ClusterRoleData{LogEntryMetadata: types.LogEntryMetadata{Timestamp: timestamp, ResourceType: "clusterrole", Name: "admin"}})
+
+	if err := sink.WriteBatch(context.Background(), entries); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, scope := range credential.scopes {
+		if scope != azureMonitorScope {
+			t.Errorf("Expected tokens for scope '%s', got '%s'", azureMonitorScope, scope)
+		}
+	}
+	if authorizations[0] != "Bearer access-token-1" {
+		t.Errorf("Expected the credential's token to be sent, got '%s'", authorizations[0])
+	}
+
+	containerUploads := uploads["Custom-KubeStateLogsInitContainer"]
+	if len(containerUploads) < 2 {
+		t.Errorf("Expected container entries to be split across requests, got %d requests", len(containerUploads))
+	}
+	var names []string
+	for _, records := range containerUploads {
+		for _, record := range records {
+			names = append(names, record["name"].(string))
+			if record["TimeGenerated"] != "2024-05-01T12:00:00Z" {
+				t.Errorf("Expected TimeGenerated '2024-05-01T12:00:00Z', got %v", record["TimeGenerated"])
+			}
+		}
+	}
+	// The retry resumes with the throttled request instead of resending the first one
+	if strings.Join(names, ",") != "init-0,init-1,init-2,init-3" {
+		t.Errorf("Expected container entries once each in order, got %v", names)
+	}
+
+	if rbacUploads := uploads["Custom-Rbac"]; len(rbacUploads) != 1 || len(rbacUploads[0]) != 1 || rbacUploads[0][0]["name"] != "admin" {
+		t.Errorf("Expected the clusterrole entry on its configured stream, got %v", rbacUploads)
+	}
+}
+
+func TestAzureMonitorSink_WriteBatch_Unsent(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if strings.HasSuffix(r.URL.Path, "/Custom-KubeStateLogsPod") {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sink, err := NewAzureMonitorSink(AzureMonitorOptions{
+		Endpoint:   server.URL,
+		RuleID:     "dcr-test",
+		Credential: &countingCredential{},
+		Timeout:    5 * time.Second,
+		Retry:      RetryOptions{MaxRetries: 1, InitialBackoff: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entries := []any{
+		&types.PodData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "pod", Name: "web"}},
+		&types.NodeData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "node", Name: "node-1"}},
+	}
+	err = sink.WriteBatch(context.Background(), entries)
+	if !isRetryable(err) {
+		t.Fatalf("Expected a retryable error, got %v", err)
+	}
+
+	// Only the node stream failed, so the pod entry is not sent again
+	if unsent := unsentEntries(err, entries); len(unsent) != 1 || unsent[0] != entries[1] {
+		t.Errorf("Expected only the node entry to be unsent, got %v", unsent)
+	}
+	if requests != 3 {
+		t.Errorf("Expected the pod request once and the node request twice, got %d requests", requests)
+	}
+}
+
+func TestPascalCase(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "pod", expected: "Pod"},
+		{input: "init_container", expected: "InitContainer"},
+		{input: "clusterrolebinding", expected: "Clusterrolebinding"},
+		{input: "widgets.mygroup.example.com", expected: "WidgetsMygroupExampleCom"},
+	}
+
+	for _, tt := range tests {
+		if result := pascalCase(tt.input); result != tt.expected {
+			t.Errorf("Expected '%s' for '%s', got '%s'", tt.expected, tt.input, result)
+		}
+	}
+}
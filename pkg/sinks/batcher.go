@@ -0,0 +1,259 @@
+package sinks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/metrics"
+)
+
+// Overflow policies applied when a BatchingSink's queue is full
+const (
+	OverflowBlock      = "block"       // Wait until the queue has room
+	OverflowDropNewest = "drop-newest" // Discard the entry being logged
+	OverflowDropOldest = "drop-oldest" // Discard the oldest queued entry to make room
+)
+
+// ErrSinkClosed is returned when logging to a sink that has been closed
+var ErrSinkClosed = errors.New("sink is closed")
+
+// BatchWriter writes a batch of entries to a destination
+type BatchWriter interface {
+	WriteBatch(ctx context.Context, entries []any) error
+}
+
+// BatchOptions configures how a BatchingSink queues and batches entries
+type BatchOptions struct {
+	QueueSize     int           // Maximum number of queued entries
+	BatchSize     int           // Maximum number of entries per batch
+	FlushInterval time.Duration // Maximum time an entry waits before its batch is written
+	Overflow      string        // Policy applied when the queue is full
+	Name          string        // Sink name that the sink's metrics are labeled with
+}
+
+// DefaultBatchOptions returns the batch options used when a sink does not configure them
+func DefaultBatchOptions() BatchOptions {
+	return BatchOptions{
+		QueueSize:     10000,
+		BatchSize:     500,
+		FlushInterval: time.Second,
+		Overflow:      OverflowDropOldest,
+	}
+}
+
+// BatchOptionsFromConfig reads the queue-size, batch-size, flush-interval and overflow
+// options of a sink, falling back to the given defaults
+func BatchOptionsFromConfig(cfg config.SinkConfig, defaults BatchOptions) (BatchOptions, error) {
+	var err error
+	opts := defaults
+
+	if opts.QueueSize, err = cfg.IntOption("queue-size", defaults.QueueSize); err != nil {
+		return opts, err
+	}
+	if opts.BatchSize, err = cfg.IntOption("batch-size", defaults.BatchSize); err != nil {
+		return opts, err
+	}
+	if opts.FlushInterval, err = cfg.DurationOption("flush-interval", defaults.FlushInterval); err != nil {
+		return opts, err
+	}
+	opts.Overflow = cfg.Option("overflow", defaults.Overflow)
+	opts.Name = cfg.Name
+
+	return opts, nil
+}
+
+// validate checks that the options describe a usable queue
+func (o BatchOptions) validate() error {
+	if o.QueueSize <= 0 {
+		return fmt.Errorf("queue size must be positive, got %d", o.QueueSize)
+	}
+	if o.BatchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", o.BatchSize)
+	}
+	if o.FlushInterval <= 0 {
+		return fmt.Errorf("flush interval must be positive, got %v", o.FlushInterval)
+	}
+	switch o.Overflow {
+	case OverflowBlock, OverflowDropNewest, OverflowDropOldest:
+		return nil
+	default:
+		return fmt.Errorf("unknown overflow policy '%s'", o.Overflow)
+	}
+}
+
+// BatchingSink serializes entries from concurrent callers through a bounded queue and
+// hands them to a BatchWriter in batches from a single goroutine
+type BatchingSink struct {
+	writer  BatchWriter
+	opts    BatchOptions
+	queue   chan any
+	done    chan struct{}
+	dropped atomic.Int64
+
+	// ctx is cancelled by Close, so that blocked callers and retries give up
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// mu guards closed and the queue against being closed while Log sends to it
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewBatchingSink creates a BatchingSink and starts its writer goroutine
+func NewBatchingSink(writer BatchWriter, opts BatchOptions) (*BatchingSink, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	s := &BatchingSink{
+		writer: writer,
+		opts:   opts,
+		queue:  make(chan any, opts.QueueSize),
+		done:   make(chan struct{}),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	metrics.SinkQueueLength.SetFunc(s.queueLength, opts.Name)
+	go s.run()
+
+	return s, nil
+}
+
+// Log queues an entry, applying the overflow policy if the queue is full
+func (s *BatchingSink) Log(entry any) error {
+	return s.LogContext(context.Background(), entry)
+}
+
+// LogContext queues an entry like Log. With the block policy, it stops waiting for room
+// when ctx is done or the sink is closed.
+func (s *BatchingSink) LogContext(ctx context.Context, entry any) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return ErrSinkClosed
+	}
+
+	switch s.opts.Overflow {
+	case OverflowDropNewest:
+		select {
+		case s.queue <- entry:
+		default:
+			s.drop()
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case s.queue <- entry:
+				return nil
+			default:
+			}
+			select {
+			case <-s.queue:
+				s.drop()
+			default:
+			}
+		}
+	default:
+		select {
+		case s.queue <- entry:
+		case <-s.ctx.Done():
+			return ErrSinkClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// queueLength returns the number of queued entries
+func (s *BatchingSink) queueLength() float64 {
+	return float64(len(s.queue))
+}
+
+// Dropped returns the number of entries discarded because the queue was full
+func (s *BatchingSink) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close stops accepting entries, writes everything still queued and closes the writer.
+// Writes in progress stop retrying, and queued entries get a single attempt.
+func (s *BatchingSink) Close() error {
+	// Cancel first so that callers blocked on a full queue release the lock
+	s.cancel()
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	<-s.done
+	metrics.SinkQueueLength.Delete(s.opts.Name)
+
+	if dropped := s.Dropped(); dropped > 0 {
+		klog.Warningf("Sink dropped %d entries because its queue was full", dropped)
+	}
+
+	if closer, ok := s.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// drop records a discarded entry, warning on the first one
+func (s *BatchingSink) drop() {
+	metrics.SinkDroppedEntries.WithLabelValues(s.opts.Name).Inc()
+	if s.dropped.Add(1) == 1 {
+		klog.Warningf("Sink queue is full, dropping entries (policy %s)", s.opts.Overflow)
+	}
+}
+
+// run batches queued entries and writes them until the queue is closed and drained
+func (s *BatchingSink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]any, 0, s.opts.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		start := time.Now()
+		if err := s.writer.WriteBatch(s.ctx, batch); err != nil {
+			metrics.SinkWriteErrors.WithLabelValues(s.opts.Name).Inc()
+			klog.Errorf("Failed to write batch of %d entries: %v", len(batch), err)
+		}
+		metrics.SinkWriteDuration.WithLabelValues(s.opts.Name).Observe(time.Since(start).Seconds())
+		batch = make([]any, 0, s.opts.BatchSize)
+	}
+
+	for {
+		select {
+		case entry, ok := <-s.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= s.opts.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
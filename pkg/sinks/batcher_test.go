@@ -0,0 +1,220 @@
+package sinks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// blockingWriter records batches and blocks each write until released
+type blockingWriter struct {
+	mu      sync.Mutex
+	release chan struct{}
+	entries []any
+}
+
+func (w *blockingWriter) WriteBatch(ctx context.Context, entries []any) error {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.entries = append(w.entries, entries...)
+	return nil
+}
+
+func TestBatchingSink_ConcurrentLog(t *testing.T) {
+	var output bytes.Buffer
+	sink, err := NewBatchingSink(NewWriterSink(&output), BatchOptions{
+		QueueSize:     10,
+		BatchSize:     7,
+		FlushInterval: time.Hour,
+		Overflow:      OverflowBlock,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	const goroutines, perGoroutine = 8, 100
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				entry := types.PodData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "pod", Name: "test-pod"}}
+				if err := sink.Log(entry); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Close must flush the final partial batch even though the flush interval never fires
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Expected no error on close, got %v", err)
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(&output)
+	for scanner.Scan() {
+		var entry map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Expected valid JSON line, got %q: %v", scanner.Text(), err)
+		}
+		lines++
+	}
+	if lines != goroutines*perGoroutine {
+		t.Errorf("Expected %d lines, got %d", goroutines*perGoroutine, lines)
+	}
+
+	if err := sink.Log(types.PodData{}); err != ErrSinkClosed {
+		t.Errorf("Expected ErrSinkClosed after close, got %v", err)
+	}
+}
+
+func TestBatchingSink_Overflow(t *testing.T) {
+	tests := []struct {
+		name          string
+		overflow      string
+		expectedNames []string
+	}{
+		{
+			name:          "drop newest keeps the queued entries",
+			overflow:      OverflowDropNewest,
+			expectedNames: []string{"first", "second", "third"},
+		},
+		{
+			name:          "drop oldest keeps the latest entries",
+			overflow:      OverflowDropOldest,
+			expectedNames: []string{"first", "fourth", "fifth"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &blockingWriter{release: make(chan struct{})}
+			sink, err := NewBatchingSink(writer, BatchOptions{
+				QueueSize:     2,
+				BatchSize:     1,
+				FlushInterval: time.Hour,
+				Overflow:      tt.overflow,
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// The first entry is taken off the queue and blocks in the writer
+			sink.Log(types.LogEntryMetadata{Name: "first"})
+			deadline := time.Now().Add(5 * time.Second)
+			for len(sink.queue) != 0 && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+
+			for _, name := range []string{"second", "third", "fourth", "fifth"} {
+				if err := sink.Log(types.LogEntryMetadata{Name: name}); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+
+			if sink.Dropped() != 2 {
+				t.Errorf("Expected 2 dropped entries, got %d", sink.Dropped())
+			}
+
+			close(writer.release)
+			if err := sink.Close(); err != nil {
+				t.Fatalf("Expected no error on close, got %v", err)
+			}
+
+			if len(writer.entries) != len(tt.expectedNames) {
+				t.Fatalf("Expected %d entries, got %d", len(tt.expectedNames), len(writer.entries))
+			}
+			for i, entry := range writer.entries {
+				if name := entry.(types.LogEntryMetadata).Name; name != tt.expectedNames[i] {
+					t.Errorf("Expected entry %d to be '%s', got '%s'", i, tt.expectedNames[i], name)
+				}
+			}
+		})
+	}
+}
+
+// unavailableWriter fails every attempt with a retryable error and a long backoff
+type unavailableWriter struct {
+	attempts atomic.Int64
+}
+
+func (w *unavailableWriter) WriteBatch(ctx context.Context, entries []any) error {
+	retry := RetryOptions{MaxRetries: 10, InitialBackoff: time.Hour}
+	return retry.Do(ctx, func() error {
+		w.attempts.Add(1)
+		return retryable(errors.New("backend unavailable"), 0)
+	})
+}
+
+func TestBatchingSink_CloseStopsRetriesAndBlockedCallers(t *testing.T) {
+	writer := &unavailableWriter{}
+	sink, err := NewBatchingSink(writer, BatchOptions{
+		QueueSize:     1,
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		Overflow:      OverflowBlock,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The first entry waits to be retried in the writer and the second fills the queue
+	sink.Log(types.LogEntryMetadata{Name: "first"})
+	deadline := time.Now().Add(5 * time.Second)
+	for writer.attempts.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	sink.Log(types.LogEntryMetadata{Name: "second"})
+
+	// Callers waiting for room give up when their context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sink.LogContext(ctx, types.LogEntryMetadata{Name: "cancelled"}); err != context.Canceled {
+		t.Errorf("Expected context.Canceled for a cancelled caller, got %v", err)
+	}
+
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- sink.Log(types.LogEntryMetadata{Name: "third"})
+	}()
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- sink.Close()
+	}()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Errorf("Expected no error on close, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Close to return while the writer was waiting to retry")
+	}
+
+	select {
+	case err := <-blocked:
+		if err != ErrSinkClosed {
+			t.Errorf("Expected ErrSinkClosed for the blocked caller, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the blocked caller to return on close")
+	}
+
+	// One attempt for the first entry and a single attempt for the queued one
+	if attempts := writer.attempts.Load(); attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
@@ -0,0 +1,151 @@
+package sinks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// Output envelopes
+const (
+	EnvelopeNone        = "none"        // Entries are written as they are
+	EnvelopeCloudEvents = "cloudevents" // Entries are wrapped in CloudEvents 1.0 structured JSON events
+)
+
+// Content types of the CloudEvents HTTP binding
+const (
+	CloudEventsContentType      = "application/cloudevents+json"       // A single event in structured mode
+	CloudEventsBatchContentType = "application/cloudevents-batch+json" // A JSON array of events in batched mode
+)
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsTypePrefix  = "io.kube-state-logs"
+	cloudEventsSnapshot    = "snapshot"
+)
+
+// CloudEventsOptions configures a CloudEventsWriter
+type CloudEventsOptions struct {
+	Source     string // Event source identifying the cluster, e.g. "/clusters/prod-westeurope"
+	TypePrefix string // Prefix of the event type, followed by the resource and event type
+}
+
+// CloudEventsWriter wraps every entry in a CloudEvents envelope before passing it on.
+// Events keep the entry's metadata, so routing and sink labels are unchanged.
+type CloudEventsWriter struct {
+	writer BatchWriter
+	opts   CloudEventsOptions
+}
+
+// cloudEvent is a CloudEvents 1.0 event in structured JSON mode
+type cloudEvent struct {
+	SpecVersion     string `json:"specversion"`
+	ID              string `json:"id"`
+	Source          string `json:"source"`
+	Type            string `json:"type"`
+	Subject         string `json:"subject,omitempty"`
+	Time            string `json:"time,omitempty"`
+	DataContentType string `json:"datacontenttype"`
+	Data            any    `json:"data"`
+
+	metadata types.LogEntryMetadata
+}
+
+// GetMetadata returns the metadata of the wrapped entry
+func (e *cloudEvent) GetMetadata() types.LogEntryMetadata {
+	return e.metadata
+}
+
+// unwrapEntry returns the entry wrapped in an event, or the entry itself if it is not an event
+func unwrapEntry(entry any) any {
+	if event, ok := entry.(*cloudEvent); ok {
+		return event.Data
+	}
+	return entry
+}
+
+// NewCloudEventsWriter creates a CloudEventsWriter
+func NewCloudEventsWriter(writer BatchWriter, opts CloudEventsOptions) (*CloudEventsWriter, error) {
+	if opts.Source == "" {
+		return nil, fmt.Errorf("cloudevents source is required")
+	}
+	if opts.TypePrefix == "" {
+		opts.TypePrefix = cloudEventsTypePrefix
+	}
+	return &CloudEventsWriter{writer: writer, opts: opts}, nil
+}
+
+// envelopeFromConfig wraps a writer in the envelope set by the sink's envelope option,
+// configured from its ce-source, ce-type-prefix and cluster-name options
+func envelopeFromConfig(cfg config.SinkConfig, writer BatchWriter) (BatchWriter, error) {
+	switch envelope := cfg.Option("envelope", EnvelopeNone); envelope {
+	case EnvelopeNone:
+		return writer, nil
+	case EnvelopeCloudEvents:
+		source := "kube-state-logs"
+		if clusterName := cfg.Option("cluster-name", ""); clusterName != "" {
+			source = "/clusters/" + clusterName
+		}
+		return NewCloudEventsWriter(writer, CloudEventsOptions{
+			Source:     cfg.Option("ce-source", source),
+			TypePrefix: cfg.Option("ce-type-prefix", cloudEventsTypePrefix),
+		})
+	default:
+		return nil, fmt.Errorf("unknown envelope '%s' for sink '%s'", envelope, cfg.Name)
+	}
+}
+
+// WriteBatch wraps entries in events and writes them
+func (w *CloudEventsWriter) WriteBatch(ctx context.Context, entries []any) error {
+	now := time.Now()
+	events := make([]any, len(entries))
+	for i, entry := range entries {
+		events[i] = w.event(entry, now)
+	}
+	return w.writer.WriteBatch(ctx, events)
+}
+
+// Close closes the wrapped writer
+func (w *CloudEventsWriter) Close() error {
+	if closer, ok := w.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// event wraps an entry. The type is "<prefix>.<resourceType>.<eventType>", where entries
+// from periodic collection have the event type "snapshot".
+func (w *CloudEventsWriter) event(entry any, now time.Time) *cloudEvent {
+	metadata, _ := types.MetadataOf(entry)
+
+	eventType := metadata.EventType
+	if eventType == "" {
+		eventType = cloudEventsSnapshot
+	}
+	timestamp := metadata.Timestamp
+	if timestamp.IsZero() {
+		timestamp = now
+	}
+	subject := metadata.Name
+	if metadata.Namespace != "" {
+		subject = metadata.Namespace + "/" + metadata.Name
+	}
+
+	return &cloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              uuid.NewString(),
+		Source:          w.opts.Source,
+		Type:            w.opts.TypePrefix + "." + metadata.ResourceType + "." + eventType,
+		Subject:         subject,
+		Time:            timestamp.UTC().Format(time.RFC3339Nano),
+		DataContentType: "application/json",
+		Data:            entry,
+		metadata:        metadata,
+	}
+}
@@ -0,0 +1,219 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+	"go.goms.io/aks/kube-state-logs/pkg/utils"
+)
+
+func TestCloudEventsWriter_WriteBatch(t *testing.T) {
+	timestamp := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	created := &types.PodData{LogEntryMetadata: types.LogEntryMetadata{Timestamp: timestamp, ResourceType: "pod", Namespace: "default", Name: "web-0"}}
+	created.SetEventType(utils.EventTypeCreate)
+
+	tests := []struct {
+		name            string
+		entry           any
+		expectedType    string
+		expectedSubject string
+		expectedName    string
+	}{
+		{
+			name:            "snapshot",
+			entry:           &types.NodeData{LogEntryMetadata: types.LogEntryMetadata{Timestamp: timestamp, ResourceType: "node", Name: "node-1"}},
+			expectedType:    "io.kube-state-logs.node.snapshot",
+			expectedSubject: "node-1",
+			expectedName:    "node-1",
+		},
+		{
+			name:            "informer event",
+			entry:           created,
+			expectedType:    "io.kube-state-logs.pod.create",
+			expectedSubject: "default/web-0",
+			expectedName:    "web-0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var batches [][]any
+			writer, err := NewCloudEventsWriter(batchRecorder(func(entries []any) {
+				batches = append(batches, entries)
+			}), CloudEventsOptions{Source: "/clusters/test"})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if err := writer.WriteBatch(context.Background(), []any{tt.entry, tt.entry}); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var events []map[string]any
+			data, _ := json.Marshal(batches[0])
+			if err := json.Unmarshal(data, &events); err != nil {
+				t.Fatalf("Failed to parse events: %v", err)
+			}
+
+			event := events[0]
+			expected := map[string]any{
+				"specversion":     "1.0",
+				"source":          "/clusters/test",
+				"type":            tt.expectedType,
+				"subject":         tt.expectedSubject,
+				"time":            "2024-05-01T12:00:00Z",
+				"datacontenttype": "application/json",
+			}
+			for key, value := range expected {
+				if event[key] != value {
+					t.Errorf("Expected %s '%v', got '%v'", key, value, event[key])
+				}
+			}
+			if data, ok := event["data"].(map[string]any); !ok || data["name"] != tt.expectedName {
+				t.Errorf("Expected the entry as data, got %v", event["data"])
+			}
+			if event["id"] == "" || event["id"] == events[1]["id"] {
+				t.Errorf("Expected unique event IDs, got '%v' and '%v'", event["id"], events[1]["id"])
+			}
+
+			// Events keep the entry's metadata for routing and labels
+			if metadata, _ := types.MetadataOf(batches[0][0]); metadata.Name != tt.expectedName {
+				t.Errorf("Expected events to keep the entry's metadata, got name '%s'", metadata.Name)
+			}
+		})
+	}
+}
+
+func TestCloudEventsEnvelope_Webhook(t *testing.T) {
+	var contentType string
+	var events []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &events)
+	}))
+	defer server.Close()
+
+	sink, err := New(config.SinkConfig{
+		Name:    "events",
+		Type:    TypeWebhook,
+		Options: map[string]string{"url": server.URL, "envelope": EnvelopeCloudEvents, "cluster-name": "prod"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := sink.Log(&types.ServiceData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "service", Namespace: "default", Name: "api"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := sink.(io.Closer).Close(); err != nil {
+		t.Fatalf("Expected no error on close, got %v", err)
+	}
+
+	if contentType != CloudEventsBatchContentType {
+		t.Errorf("Expected content type '%s', got '%s'", CloudEventsBatchContentType, contentType)
+	}
+	if len(events) != 1 || events[0]["source"] != "/clusters/prod" || events[0]["type"] != "io.kube-state-logs.service.snapshot" {
+		t.Errorf("Expected one service event from /clusters/prod, got %v", events)
+	}
+}
+
+func TestCloudEventsEnvelope_WebhookSingle(t *testing.T) {
+	var contentTypes []string
+	var events []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		var event map[string]any
+		if err := json.Unmarshal(body, &event); err != nil {
+			http.Error(w, "expected a single event", http.StatusBadRequest)
+			return
+		}
+		events = append(events, event)
+	}))
+	defer server.Close()
+
+	sink, err := New(config.SinkConfig{
+		Name:    "broker",
+		Type:    TypeWebhook,
+		Options: map[string]string{"url": server.URL, "format": WebhookFormatSingle, "envelope": EnvelopeCloudEvents, "cluster-name": "prod"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, name := range []string{"api", "web"} {
+		if err := sink.Log(&types.ServiceData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "service", Namespace: "default", Name: name}}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if err := sink.(io.Closer).Close(); err != nil {
+		t.Fatalf("Expected no error on close, got %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected one request per event, got %d events", len(events))
+	}
+	for i, event := range events {
+		if contentTypes[i] != CloudEventsContentType {
+			t.Errorf("Expected content type '%s', got '%s'", CloudEventsContentType, contentTypes[i])
+		}
+		if event["specversion"] != "1.0" || event["type"] != "io.kube-state-logs.service.snapshot" {
+			t.Errorf("Expected a structured service event, got %v", event)
+		}
+	}
+	if events[0]["subject"] != "default/api" || events[1]["subject"] != "default/web" {
+		t.Errorf("Expected events for default/api and default/web in order, got %v and %v", events[0]["subject"], events[1]["subject"])
+	}
+}
+
+func TestCloudEventsEnvelope_Loki(t *testing.T) {
+	var streams map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		streams = decodeLokiPush(t, r)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink, err := New(config.SinkConfig{
+		Name: "events",
+		Type: TypeLoki,
+		Options: map[string]string{
+			"endpoint": server.URL, "labels": "createdByKind", "encoding": LokiEncodingNone,
+			"envelope": EnvelopeCloudEvents, "cluster-name": "prod",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	entry := &types.PodData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "pod", Namespace: "default", Name: "web-0", CreatedByKind: "StatefulSet"}}
+	if err := sink.Log(entry); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := sink.(io.Closer).Close(); err != nil {
+		t.Fatalf("Expected no error on close, got %v", err)
+	}
+
+	// Labels come from the wrapped entry, and lines are the events
+	stream := `{created_by_kind="StatefulSet", namespace="default", resource_type="pod"}`
+	if len(streams) != 1 || len(streams[stream]) != 1 {
+		t.Fatalf("Expected one line in stream %s, got %v", stream, streams)
+	}
+	if !strings.Contains(streams[stream][0], `"type":"io.kube-state-logs.pod.snapshot"`) {
+		t.Errorf("Expected the line to be a pod event, got %s", streams[stream][0])
+	}
+}
+
+// batchRecorder is a BatchWriter that passes every batch to a function
+type batchRecorder func(entries []any)
+
+func (r batchRecorder) WriteBatch(ctx context.Context, entries []any) error {
+	r(entries)
+	return nil
+}
@@ -0,0 +1,294 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+const (
+	elasticsearchBulkPath     = "/_bulk"
+	elasticsearchDefaultIndex = "kube-state-logs-{resourceType}-{yyyy.MM.dd}"
+)
+
+// elasticsearchDateTokens maps index pattern date tokens to Go time layout elements
+var elasticsearchDateTokens = strings.NewReplacer(
+	"yyyy", "2006",
+	"MM", "01",
+	"dd", "02",
+	"HH", "15",
+)
+
+// ElasticsearchOptions configures an ElasticsearchSink
+type ElasticsearchOptions struct {
+	Endpoint    string            // e.g. "https://elasticsearch.logging:9200"
+	Index       string            // Index pattern, e.g. "kube-state-logs-{resourceType}-{yyyy.MM.dd}"
+	DocumentIDs bool              // Derive document IDs from the entry so retried documents are not duplicated
+	Username    string            // Basic authentication, if set
+	Password    string            // Basic authentication password
+	APIKey      string            // Encoded API key, if set
+	Headers     map[string]string // Extra request headers
+	Timeout     time.Duration     // Timeout for a single bulk request
+	Retry       RetryOptions
+}
+
+// ElasticsearchSink writes entries to Elasticsearch or OpenSearch with the _bulk API.
+// Documents rejected with a transient status are retried; other rejected documents are
+// logged and dropped so one bad document cannot block the rest.
+type ElasticsearchSink struct {
+	opts   ElasticsearchOptions
+	url    string
+	client *http.Client
+}
+
+// elasticsearchDocument is a single bulk action and its source
+type elasticsearchDocument struct {
+	action []byte
+	source []byte
+}
+
+// elasticsearchBulkResponse is the part of a _bulk response used to find failed documents
+type elasticsearchBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+// NewElasticsearchSink creates an ElasticsearchSink
+func NewElasticsearchSink(opts ElasticsearchOptions) (*ElasticsearchSink, error) {
+	if opts.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+	if opts.Index == "" {
+		opts.Index = elasticsearchDefaultIndex
+	}
+
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint '%s': %w", opts.Endpoint, err)
+	}
+	endpoint.Path = strings.TrimSuffix(endpoint.Path, "/") + elasticsearchBulkPath
+
+	return &ElasticsearchSink{
+		opts:   opts,
+		url:    endpoint.String(),
+		client: &http.Client{Timeout: opts.Timeout},
+	}, nil
+}
+
+// newElasticsearchSink creates an ElasticsearchSink from a sink's endpoint, index,
+// document-ids, username, password, api-key, headers, timeout and retry options
+func newElasticsearchSink(cfg config.SinkConfig) (*ElasticsearchSink, error) {
+	documentIDs, err := cfg.BoolOption("document-ids", true)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := cfg.DurationOption("timeout", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := parsePairs(cfg.Option("headers", ""))
+	if err != nil {
+		return nil, err
+	}
+	retry, err := RetryOptionsFromConfig(cfg, DefaultRetryOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewElasticsearchSink(ElasticsearchOptions{
+		Endpoint:    cfg.Option("endpoint", ""),
+		Index:       cfg.Option("index", elasticsearchDefaultIndex),
+		DocumentIDs: documentIDs,
+		Username:    cfg.Option("username", ""),
+		Password:    cfg.Option("password", ""),
+		APIKey:      cfg.Option("api-key", ""),
+		Headers:     headers,
+		Timeout:     timeout,
+		Retry:       retry,
+	})
+}
+
+// WriteBatch indexes entries with one bulk request, retrying the request on throttling
+// and server errors and retrying only the documents that failed transiently
+func (s *ElasticsearchSink) WriteBatch(ctx context.Context, entries []any) error {
+	pending := make([]elasticsearchDocument, 0, len(entries))
+	for _, entry := range entries {
+		document, err := s.document(entry)
+		if err != nil {
+			return err
+		}
+		pending = append(pending, document)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	return s.opts.Retry.Do(ctx, func() error {
+		failed, err := s.bulk(pending)
+		if err != nil {
+			return err
+		}
+		if len(failed) > 0 {
+			err := fmt.Errorf("%d of %d documents were rejected with a transient error", len(failed), len(pending))
+			pending = failed
+			return retryable(err, 0)
+		}
+		return nil
+	})
+}
+
+// document builds the bulk action and source for an entry
+func (s *ElasticsearchSink) document(entry any) (elasticsearchDocument, error) {
+	source, err := json.Marshal(entry)
+	if err != nil {
+		return elasticsearchDocument{}, fmt.Errorf("failed to marshal entry of type %T: %w", entry, err)
+	}
+
+	metadata, _ := types.MetadataOf(entry)
+	if metadata.Timestamp.IsZero() {
+		metadata.Timestamp = time.Now()
+	}
+
+	target := map[string]string{"_index": s.index(metadata)}
+	if s.opts.DocumentIDs {
+		target["_id"] = documentID(metadata)
+	}
+	action, err := json.Marshal(map[string]any{"index": target})
+	if err != nil {
+		return elasticsearchDocument{}, err
+	}
+
+	return elasticsearchDocument{action: action, source: source}, nil
+}
+
+// index expands the index pattern for an entry. {resourceType}, {namespace} and {name}
+// are replaced with the entry's metadata; any other {token} is a date format using
+// yyyy, MM, dd and HH, evaluated in UTC at the entry's timestamp.
+func (s *ElasticsearchSink) index(metadata types.LogEntryMetadata) string {
+	var index strings.Builder
+	pattern := s.opts.Index
+
+	for {
+		start := strings.Index(pattern, "{")
+		end := strings.Index(pattern, "}")
+		if start < 0 || end < start {
+			index.WriteString(pattern)
+			break
+		}
+		index.WriteString(pattern[:start])
+
+		token := pattern[start+1 : end]
+		switch token {
+		case "resourceType":
+			index.WriteString(metadata.ResourceType)
+		case "namespace":
+			if metadata.Namespace == "" {
+				index.WriteString("cluster")
+			} else {
+				index.WriteString(metadata.Namespace)
+			}
+		case "name":
+			index.WriteString(metadata.Name)
+		default:
+			index.WriteString(metadata.Timestamp.UTC().Format(elasticsearchDateTokens.Replace(token)))
+		}
+
+		pattern = pattern[end+1:]
+	}
+
+	// Index names must be lowercase
+	return strings.ToLower(index.String())
+}
+
+// bulk sends documents in a single _bulk request and returns the documents that
+// failed transiently. Documents that failed permanently are logged and dropped.
+func (s *ElasticsearchSink) bulk(documents []elasticsearchDocument) ([]elasticsearchDocument, error) {
+	var body bytes.Buffer
+	for _, document := range documents {
+		body.Write(document.action)
+		body.WriteByte('\n')
+		body.Write(document.source)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.opts.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+s.opts.APIKey)
+	} else if s.opts.Username != "" {
+		req.SetBasicAuth(s.opts.Username, s.opts.Password)
+	}
+	for key, value := range s.opts.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, retryable(fmt.Errorf("failed to send bulk request: %w", err), 0)
+	}
+	defer resp.Body.Close()
+
+	if err := checkHTTPResponse(resp); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, retryable(fmt.Errorf("failed to read bulk response: %w", err), 0)
+	}
+
+	var response elasticsearchBulkResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse bulk response: %w", err)
+	}
+	if !response.Errors {
+		return nil, nil
+	}
+	if len(response.Items) != len(documents) {
+		return nil, fmt.Errorf("bulk response has %d items for %d documents", len(response.Items), len(documents))
+	}
+
+	var failed []elasticsearchDocument
+	for i, item := range response.Items {
+		for _, result := range item {
+			switch {
+			case result.Status < 300:
+			case result.Status == http.StatusTooManyRequests || result.Status >= 500:
+				failed = append(failed, documents[i])
+			default:
+				klog.Errorf("Elasticsearch rejected document with status %d: %s", result.Status, result.Error)
+			}
+		}
+	}
+
+	return failed, nil
+}
+
+// documentID derives a stable ID from an entry's identity and timestamp, so a retried
+// document overwrites itself instead of being indexed twice. The owner is part of the
+// identity because container entries are only unique within their pod.
+func documentID(metadata types.LogEntryMetadata) string {
+	key := fmt.Sprintf("%s/%s/%s/%s/%s/%d", metadata.ResourceType, metadata.Namespace, metadata.CreatedByKind,
+		metadata.CreatedByName, metadata.Name, metadata.Timestamp.UnixNano())
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,132 @@
+package sinks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+func TestElasticsearchSink_Index(t *testing.T) {
+	timestamp := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		pattern  string
+		metadata types.LogEntryMetadata
+		expected string
+	}{
+		{
+			name:     "default pattern",
+			pattern:  elasticsearchDefaultIndex,
+			metadata: types.LogEntryMetadata{Timestamp: timestamp, ResourceType: "deployment"},
+			expected: "kube-state-logs-deployment-2024.01.15",
+		},
+		{
+			name:     "namespace and hourly date",
+			pattern:  "ksl-{namespace}-{yyyy.MM.dd.HH}",
+			metadata: types.LogEntryMetadata{Timestamp: timestamp, ResourceType: "pod", Namespace: "Kube-System"},
+			expected: "ksl-kube-system-2024.01.15.10",
+		},
+		{
+			name:     "cluster-scoped namespace",
+			pattern:  "ksl-{namespace}",
+			metadata: types.LogEntryMetadata{Timestamp: timestamp, ResourceType: "node"},
+			expected: "ksl-cluster",
+		},
+		{
+			name:     "static index",
+			pattern:  "kube-state-logs",
+			metadata: types.LogEntryMetadata{Timestamp: timestamp, ResourceType: "node"},
+			expected: "kube-state-logs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, err := NewElasticsearchSink(ElasticsearchOptions{Endpoint: "http://localhost:9200", Index: tt.pattern})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if index := sink.index(tt.metadata); index != tt.expected {
+				t.Errorf("Expected index '%s', got '%s'", tt.expected, index)
+			}
+		})
+	}
+}
+
+func TestElasticsearchSink_PartialFailure(t *testing.T) {
+	var requests [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != elasticsearchBulkPath || r.Header.Get("Content-Type") != "application/x-ndjson" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+
+		// Collect the document names in the request
+		var names []string
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var action map[string]map[string]string
+			json.Unmarshal(scanner.Bytes(), &action)
+			if action["index"]["_id"] == "" {
+				http.Error(w, "missing document ID", http.StatusBadRequest)
+				return
+			}
+			scanner.Scan()
+			var source map[string]any
+			json.Unmarshal(scanner.Bytes(), &source)
+			names = append(names, source["name"].(string))
+		}
+		requests = append(requests, names)
+
+		// On the first request, throttle "retried" and reject "invalid"
+		var items []string
+		for _, name := range names {
+			status := http.StatusCreated
+			if len(requests) == 1 && name == "retried" {
+				status = http.StatusTooManyRequests
+			} else if name == "invalid" {
+				status = http.StatusBadRequest
+			}
+			items = append(items, fmt.Sprintf(`{"index":{"status":%d}}`, status))
+		}
+		fmt.Fprintf(w, `{"errors":true,"items":[%s]}`, strings.Join(items, ","))
+	}))
+	defer server.Close()
+
+	sink, err := NewElasticsearchSink(ElasticsearchOptions{
+		Endpoint:    server.URL,
+		DocumentIDs: true,
+		Timeout:     5 * time.Second,
+		Retry:       RetryOptions{MaxRetries: 2, InitialBackoff: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var entries []any
+	for _, name := range []string{"indexed", "retried", "invalid"} {
+		entries = append(entries, &types.DeploymentData{LogEntryMetadata: types.LogEntryMetadata{
+			Timestamp: time.Now(), ResourceType: "deployment", Name: name, Namespace: "default",
+		}})
+	}
+
+	if err := sink.WriteBatch(context.Background(), entries); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 bulk requests, got %d", len(requests))
+	}
+	if len(requests[1]) != 1 || requests[1][0] != "retried" {
+		t.Errorf("Expected only the throttled document to be retried, got %v", requests[1])
+	}
+}
@@ -0,0 +1,440 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// Output formats
+const (
+	OutputFormatJSON     = "json"      // Nested JSON as produced by the collectors
+	OutputFormatFlatJSON = "flat-json" // JSON with nested fields flattened to dotted top-level keys
+	OutputFormatLogfmt   = "logfmt"    // Flattened key=value pairs
+	OutputFormatCSV      = "csv"       // One row per entry under a header, for sinks routed a single resource type
+)
+
+// lineOutputFormats lists the sink types that write text lines and the output formats
+// they support beyond JSON and flattened JSON, which every sink supports
+var lineOutputFormats = map[string][]string{
+	TypeStdout:       {OutputFormatLogfmt, OutputFormatCSV},
+	TypeFile:         {OutputFormatLogfmt, OutputFormatCSV},
+	TypeRotatingFile: {OutputFormatLogfmt, OutputFormatCSV},
+	TypeLoki:         {OutputFormatLogfmt},
+	TypeSyslog:       {OutputFormatLogfmt},
+	TypeWebhook:      {OutputFormatLogfmt},
+}
+
+// Encoder serializes entries as lines of text
+type Encoder interface {
+	// Encode appends an entry to buf without a trailing newline. Encoders that need a
+	// header write it before the entry, separated by a newline.
+	Encode(buf *bytes.Buffer, entry any) error
+}
+
+// resettableEncoder is an Encoder whose output depends on what it wrote before, such as
+// a header. Reset starts over, e.g. when the output moves to a new file, and Continue
+// carries on after the output of a previous run, e.g. in a file that is appended to.
+type resettableEncoder interface {
+	Encoder
+	Reset()
+	Continue()
+}
+
+// NewEncoder creates an Encoder for an output format. Flattened JSON is encoded as JSON;
+// entries are flattened before they reach the sink.
+func NewEncoder(format string) (Encoder, error) {
+	switch format {
+	case OutputFormatJSON, OutputFormatFlatJSON:
+		return jsonEncoder{}, nil
+	case OutputFormatLogfmt:
+		return logfmtEncoder{}, nil
+	case OutputFormatCSV:
+		return &csvEncoder{}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format '%s'", format)
+	}
+}
+
+// encoderFromConfig creates an Encoder for the sink's output-format option, checking
+// that the sink type supports it. CSV sinks must be routed exactly one resource type,
+// so that every row matches the header.
+func encoderFromConfig(cfg config.SinkConfig) (Encoder, error) {
+	format := cfg.Option("output-format", OutputFormatJSON)
+	if (format == OutputFormatLogfmt || format == OutputFormatCSV) && !slices.Contains(lineOutputFormats[cfg.Type], format) {
+		return nil, fmt.Errorf("output format '%s' is not supported by sink type '%s'", format, cfg.Type)
+	}
+	if format == OutputFormatCSV && len(cfg.Resources) != 1 {
+		return nil, fmt.Errorf("output format '%s' of sink '%s' needs exactly one resource type in its resources option, got %d", format, cfg.Name, len(cfg.Resources))
+	}
+	return NewEncoder(format)
+}
+
+// flattenFromConfig wraps a writer in a FlatteningWriter if the sink's output-format
+// option is flat-json
+func flattenFromConfig(cfg config.SinkConfig, writer BatchWriter) BatchWriter {
+	if cfg.Option("output-format", OutputFormatJSON) != OutputFormatFlatJSON {
+		return writer
+	}
+	return NewFlatteningWriter(writer)
+}
+
+// jsonEncoder encodes entries as JSON
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encode(buf *bytes.Buffer, entry any) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal entry of type %T: %w", entry, err)
+	}
+	buf.Write(data)
+	return nil
+}
+
+// logfmtEncoder encodes entries as logfmt key=value pairs of their flattened fields
+type logfmtEncoder struct{}
+
+func (logfmtEncoder) Encode(buf *bytes.Buffer, entry any) error {
+	fields, err := flattenEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(logfmtKey(field.key))
+		buf.WriteByte('=')
+		buf.WriteString(logfmtValue(field.value))
+	}
+	return nil
+}
+
+// logfmtKey replaces characters that are not allowed in logfmt keys
+func logfmtKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == unicode.ReplacementChar {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtValue formats a flattened value, quoting strings that need it
+func logfmtValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		if v == "" || strings.ContainsAny(v, " =\"\\") || strings.ContainsFunc(v, unicode.IsControl) {
+			return strconv.Quote(v)
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// csvEncoder encodes entries as CSV rows. The columns are the JSON fields of the entry's
+// type in declaration order, so the header of a resource type is stable. A header is
+// written before the first row, unless the output already starts with one, and whenever
+// the columns change.
+type csvEncoder struct {
+	header    string
+	continued bool // The output already holds a header from a previous run
+}
+
+func (e *csvEncoder) Encode(buf *bytes.Buffer, entry any) error {
+	columns, err := csvColumns(entry)
+	if err != nil {
+		return err
+	}
+	fields, err := entryFields(entry)
+	if err != nil {
+		return err
+	}
+
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		if row[i], err = csvValue(fields[column]); err != nil {
+			return fmt.Errorf("failed to encode field '%s' of type %T: %w", column, entry, err)
+		}
+	}
+
+	var out bytes.Buffer
+	writer := csv.NewWriter(&out)
+	if header := strings.Join(columns, ","); header != e.header {
+		if !e.continued {
+			writer.Write(columns)
+		}
+		e.header = header
+		e.continued = false
+	}
+	writer.Write(row)
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+
+	buf.Write(bytes.TrimSuffix(out.Bytes(), []byte("\n")))
+	return nil
+}
+
+func (e *csvEncoder) Reset() {
+	e.header = ""
+	e.continued = false
+}
+
+func (e *csvEncoder) Continue() {
+	e.header = ""
+	e.continued = true
+}
+
+// csvColumnCache holds the columns of each entry type
+var csvColumnCache sync.Map
+
+// csvColumns returns the columns of an entry. Types with their own JSON encoding, such
+// as spooled entries, use the top-level keys of that encoding.
+func csvColumns(entry any) ([]string, error) {
+	if _, ok := entry.(json.Marshaler); ok {
+		return jsonKeys(entry)
+	}
+
+	entryType := reflect.TypeOf(entry)
+	if columns, ok := csvColumnCache.Load(entryType); ok {
+		return columns.([]string), nil
+	}
+	columns := structColumns(entryType)
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("cannot derive CSV columns for entry of type %T", entry)
+	}
+	csvColumnCache.Store(entryType, columns)
+	return columns, nil
+}
+
+// jsonKeys returns the top-level keys of an entry's JSON in order
+func jsonKeys(entry any) ([]string, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal entry of type %T: %w", entry, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("entry of type %T is not a JSON object", entry)
+	}
+
+	var keys []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		keys = append(keys, token.(string))
+	}
+	return keys, nil
+}
+
+// structColumns returns the JSON field names of a struct type in declaration order,
+// including the fields of embedded structs such as types.LogEntryMetadata
+func structColumns(t reflect.Type) []string {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var columns []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			columns = append(columns, structColumns(field.Type)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns = append(columns, name)
+	}
+	return columns
+}
+
+// csvValue formats a field as a CSV cell. Nested values are written as compact JSON.
+func csvValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		data, err := json.Marshal(v)
+		return string(data), err
+	}
+}
+
+// flatField is a flattened field of an entry. The value is a string, json.Number, bool or nil.
+type flatField struct {
+	key   string
+	value any
+}
+
+// flattenEntry returns the fields of an entry's JSON in order, with nested objects and
+// arrays flattened to dotted keys such as "labels.app" or "containers.0.name". Empty
+// objects and arrays have no fields.
+func flattenEntry(entry any) ([]flatField, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal entry of type %T: %w", entry, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("entry of type %T is not a JSON object", entry)
+	}
+
+	var fields []flatField
+	if err := flattenObject(decoder, "", &fields); err != nil {
+		return nil, fmt.Errorf("failed to flatten entry of type %T: %w", entry, err)
+	}
+	return fields, nil
+}
+
+// flattenObject flattens the members of an object whose opening delimiter was read
+func flattenObject(decoder *json.Decoder, prefix string, fields *[]flatField) error {
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if err := flattenValue(decoder, prefix+token.(string), fields); err != nil {
+			return err
+		}
+	}
+	_, err := decoder.Token()
+	return err
+}
+
+// flattenValue flattens the next value in the decoder under key
+func flattenValue(decoder *json.Decoder, key string, fields *[]flatField) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		return flattenObject(decoder, key+".", fields)
+	case json.Delim('['):
+		for i := 0; decoder.More(); i++ {
+			if err := flattenValue(decoder, key+"."+strconv.Itoa(i), fields); err != nil {
+				return err
+			}
+		}
+		_, err := decoder.Token()
+		return err
+	default:
+		*fields = append(*fields, flatField{key: key, value: token})
+		return nil
+	}
+}
+
+// FlatteningWriter flattens entries before passing them on, so sinks receive JSON
+// without nested objects. Flattened entries keep their metadata for routing and labels.
+type FlatteningWriter struct {
+	writer BatchWriter
+}
+
+// flatEntry is an entry flattened to top-level fields
+type flatEntry struct {
+	metadata types.LogEntryMetadata
+	fields   []flatField
+}
+
+// NewFlatteningWriter creates a FlatteningWriter
+func NewFlatteningWriter(writer BatchWriter) *FlatteningWriter {
+	return &FlatteningWriter{writer: writer}
+}
+
+// WriteBatch flattens entries and writes them
+func (w *FlatteningWriter) WriteBatch(ctx context.Context, entries []any) error {
+	flattened := make([]any, len(entries))
+	for i, entry := range entries {
+		fields, err := flattenEntry(entry)
+		if err != nil {
+			return err
+		}
+		metadata, _ := types.MetadataOf(entry)
+		flattened[i] = &flatEntry{metadata: metadata, fields: fields}
+	}
+	return w.writer.WriteBatch(ctx, flattened)
+}
+
+// Close closes the wrapped writer
+func (w *FlatteningWriter) Close() error {
+	if closer, ok := w.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// GetMetadata returns the metadata of the original entry
+func (e *flatEntry) GetMetadata() types.LogEntryMetadata {
+	return e.metadata
+}
+
+// MarshalJSON returns the flattened fields as a JSON object, in the original field order
+func (e *flatEntry) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range e.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
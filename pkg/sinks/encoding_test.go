@@ -0,0 +1,261 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+func testService(name string) *types.ServiceData {
+	return &types.ServiceData{
+		LogEntryMetadata: types.LogEntryMetadata{
+			ResourceType: "service",
+			Namespace:    "default",
+			Name:         name,
+			Labels:       map[string]string{"app": name},
+			Annotations:  map[string]string{"description": "public api"},
+		},
+		Type:  "ClusterIP",
+		Ports: []types.ServicePortData{{Name: "http", Protocol: "TCP", Port: 80}},
+	}
+}
+
+func TestFlatteningWriter_WriteBatch(t *testing.T) {
+	ready := true
+	node := &types.NodeData{
+		LogEntryMetadata: types.LogEntryMetadata{ResourceType: "node", Name: "node-1"},
+		Conditions:       map[string]*bool{"Ready": &ready},
+	}
+
+	var batches [][]any
+	writer := NewFlatteningWriter(batchRecorder(func(entries []any) {
+		batches = append(batches, entries)
+	}))
+	if err := writer.WriteBatch(context.Background(), []any{testService("api"), node}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var entries []map[string]any
+	data, _ := json.Marshal(batches[0])
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("Failed to parse flattened entries: %v", err)
+	}
+
+	expected := []map[string]any{
+		{"labels.app": "api", "annotations.description": "public api", "ports.0.name": "http", "ports.0.port": float64(80), "type": "ClusterIP"},
+		{"conditions.Ready": true, "name": "node-1"},
+	}
+	for i, fields := range expected {
+		for key, value := range fields {
+			if entries[i][key] != value {
+				t.Errorf("Expected %s to be %v, got %v", key, value, entries[i][key])
+			}
+		}
+		for key, value := range entries[i] {
+			if _, nested := value.(map[string]any); nested {
+				t.Errorf("Expected no nested objects, got %s: %v", key, value)
+			}
+		}
+	}
+
+	if metadata, _ := types.MetadataOf(batches[0][1]); metadata.ResourceType != "node" {
+		t.Errorf("Expected flattened entries to keep their metadata, got resource type '%s'", metadata.ResourceType)
+	}
+}
+
+func TestLogfmtEncoder_Encode(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (logfmtEncoder{}).Encode(&buf, testService("api")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	line := buf.String()
+
+	if !strings.HasPrefix(line, "timestamp=0001-01-01T00:00:00Z resourceType=service name=api namespace=default ") {
+		t.Errorf("Expected fields in declaration order, got '%s'", line)
+	}
+	for _, pair := range []string{`labels.app=api`, `annotations.description="public api"`, `createdByKind=""`, `ports.0.port=80`, `selector=null`} {
+		if !strings.Contains(line, " "+pair) {
+			t.Errorf("Expected '%s' in '%s'", pair, line)
+		}
+	}
+	if strings.Contains(line, "\n") {
+		t.Errorf("Expected a single line, got '%s'", line)
+	}
+}
+
+func TestCSVEncoder_Encode(t *testing.T) {
+	encoder := &csvEncoder{}
+	var buf bytes.Buffer
+
+	entries := []any{
+		testService("api"),
+		testService("web"),
+		&types.NodeData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "node", Name: "node-1"}},
+	}
+	for _, entry := range entries {
+		if err := encoder.Encode(&buf, entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		buf.WriteByte('\n')
+	}
+
+	reader := csv.NewReader(&buf)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+
+	// A header for the services, both services, then a header and row for the node
+	if len(rows) != 5 {
+		t.Fatalf("Expected 5 rows, got %d: %v", len(rows), rows)
+	}
+	serviceHeader := strings.Join(rows[0], ",")
+	if !strings.HasPrefix(serviceHeader, "timestamp,resourceType,name,namespace,createdTimestamp,labels,annotations,") ||
+		!strings.Contains(serviceHeader, ",type,clusterIP,externalIP,loadBalancerIP,ports,selector,endpointsCount,") {
+		t.Errorf("Expected the service header in declaration order, got '%s'", serviceHeader)
+	}
+	if rows[3][0] != "timestamp" || rows[3][2] != "name" {
+		t.Errorf("Expected a node header, got %v", rows[3])
+	}
+
+	service := map[string]string{}
+	for i, column := range rows[0] {
+		service[column] = rows[1][i]
+	}
+	expected := map[string]string{
+		"name":   "api",
+		"labels": `{"app":"api"}`,
+		"ports":  `[{"name":"http","nodePort":0,"port":80,"protocol":"TCP","targetPort":0}]`,
+		"type":   "ClusterIP",
+	}
+	for column, value := range expected {
+		if service[column] != value {
+			t.Errorf("Expected %s to be '%s', got '%s'", column, value, service[column])
+		}
+	}
+	if rows[2][2] != "web" {
+		t.Errorf("Expected the second service without a repeated header, got %v", rows[2])
+	}
+}
+
+func TestWriterSink_CSVRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "services.csv")
+	file, err := NewRotatingFile(path, RotationOptions{MaxSize: 1024, MaxFiles: 10})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sink := NewWriterSink(file)
+	sink.SetEncoder(&csvEncoder{})
+	defer sink.Close()
+
+	for range 5 {
+		if err := sink.WriteBatch(context.Background(), []any{testService("api"), testService("web")}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	files, _ := filepath.Glob(path + "*")
+	if len(files) < 2 {
+		t.Fatalf("Expected the file to rotate, got %v", files)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file, err)
+		}
+		if !bytes.HasPrefix(data, []byte("timestamp,resourceType,")) {
+			t.Errorf("Expected %s to start with a header, got '%s'", file, data[:min(len(data), 40)])
+		}
+		if headers := bytes.Count(data, []byte("timestamp,resourceType,")); headers != 1 {
+			t.Errorf("Expected one header in %s, got %d", file, headers)
+		}
+	}
+}
+
+func TestWriterSink_CSVHeaderAcrossRestarts(t *testing.T) {
+	tests := []struct {
+		name string
+		open func(path string) (*WriterSink, error)
+	}{
+		{name: "file", open: NewFileSink},
+		{name: "rotating file", open: func(path string) (*WriterSink, error) {
+			file, err := NewRotatingFile(path, RotationOptions{MaxSize: 1 << 20, MaxFiles: 10})
+			if err != nil {
+				return nil, err
+			}
+			return NewWriterSink(file), nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "services.csv")
+
+			// Each run appends to the file left by the previous one
+			for _, name := range []string{"api", "web"} {
+				sink, err := tt.open(path)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				sink.SetEncoder(&csvEncoder{})
+				if err := sink.WriteBatch(context.Background(), []any{testService(name)}); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				sink.Close()
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", path, err)
+			}
+			rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+			if err != nil {
+				t.Fatalf("Failed to parse CSV: %v", err)
+			}
+			if len(rows) != 3 || rows[0][2] != "name" || rows[1][2] != "api" || rows[2][2] != "web" {
+				t.Errorf("Expected one header followed by rows for api and web, got %v", rows)
+			}
+		})
+	}
+}
+
+func TestEncoderFromConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		sinkType    string
+		format      string
+		resources   []string
+		expectError bool
+	}{
+		{name: "default", sinkType: TypeStdout, format: "", expectError: false},
+		{name: "csv file", sinkType: TypeRotatingFile, format: OutputFormatCSV, resources: []string{"pod"}, expectError: false},
+		{name: "csv without resources", sinkType: TypeRotatingFile, format: OutputFormatCSV, expectError: true},
+		{name: "csv with several resources", sinkType: TypeFile, format: OutputFormatCSV, resources: []string{"pod", "node"}, expectError: true},
+		{name: "logfmt loki", sinkType: TypeLoki, format: OutputFormatLogfmt, expectError: false},
+		{name: "flat json elasticsearch", sinkType: TypeElasticsearch, format: OutputFormatFlatJSON, expectError: false},
+		{name: "csv loki", sinkType: TypeLoki, format: OutputFormatCSV, expectError: true},
+		{name: "logfmt elasticsearch", sinkType: TypeElasticsearch, format: OutputFormatLogfmt, expectError: true},
+		{name: "unknown", sinkType: TypeStdout, format: "xml", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := encoderFromConfig(config.SinkConfig{Name: "test", Type: tt.sinkType, Options: map[string]string{"output-format": tt.format}, Resources: tt.resources})
+			if tt.expectError && err == nil {
+				t.Errorf("Expected an error for output format '%s' on sink type '%s'", tt.format, tt.sinkType)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package sinks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// entryFields returns the JSON fields of an entry. Numbers are kept as json.Number so
+// integers survive without a float64 round trip.
+func entryFields(entry any) (map[string]any, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal entry of type %T: %w", entry, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var fields map[string]any
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal entry of type %T: %w", entry, err)
+	}
+	return fields, nil
+}
@@ -0,0 +1,361 @@
+package sinks
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// Loki push request encodings
+const (
+	LokiEncodingSnappy = "snappy" // Snappy-compressed protobuf
+	LokiEncodingGzip   = "gzip"   // Gzip-compressed JSON
+	LokiEncodingNone   = "none"   // Uncompressed JSON
+)
+
+const lokiPushPath = "/loki/api/v1/push"
+
+// LokiOptions configures a LokiSink
+type LokiOptions struct {
+	Endpoint string            // e.g. "http://loki-gateway.loki:3100"
+	Tenant   string            // X-Scope-OrgID header for multi-tenant Loki, if set
+	Labels   []string          // Entry fields used as stream labels in addition to resourceType and namespace
+	Encoding string            // LokiEncodingSnappy, LokiEncodingGzip or LokiEncodingNone
+	Encoder  Encoder           // Encoder for the log lines, JSON if nil
+	Headers  map[string]string // Extra request headers, e.g. for authentication
+	Timeout  time.Duration     // Timeout for a single push request
+	Retry    RetryOptions
+}
+
+// LokiSink pushes entries to the Loki push API. Entries are grouped into streams by
+// their resource type, namespace and configured label fields, and sent as JSON or logfmt lines.
+type LokiSink struct {
+	opts   LokiOptions
+	url    string
+	client *http.Client
+}
+
+// lokiStream is a set of lines sharing the same labels
+type lokiStream struct {
+	labels  map[string]string
+	entries []lokiEntry
+}
+
+// lokiEntry is a single log line
+type lokiEntry struct {
+	timestamp time.Time
+	line      string
+}
+
+// NewLokiSink creates a LokiSink
+func NewLokiSink(opts LokiOptions) (*LokiSink, error) {
+	if opts.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+	switch opts.Encoding {
+	case LokiEncodingSnappy, LokiEncodingGzip, LokiEncodingNone:
+	default:
+		return nil, fmt.Errorf("unknown Loki encoding '%s'", opts.Encoding)
+	}
+	if opts.Encoder == nil {
+		opts.Encoder = jsonEncoder{}
+	}
+
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint '%s': %w", opts.Endpoint, err)
+	}
+	if endpoint.Path == "" || endpoint.Path == "/" {
+		endpoint.Path = lokiPushPath
+	}
+
+	return &LokiSink{
+		opts:   opts,
+		url:    endpoint.String(),
+		client: &http.Client{Timeout: opts.Timeout},
+	}, nil
+}
+
+// newLokiSink creates a LokiSink from a sink's endpoint, tenant, labels, encoding,
+// output-format, headers, timeout and retry options
+func newLokiSink(cfg config.SinkConfig) (*LokiSink, error) {
+	timeout, err := cfg.DurationOption("timeout", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := parsePairs(cfg.Option("headers", ""))
+	if err != nil {
+		return nil, err
+	}
+	retry, err := RetryOptionsFromConfig(cfg, DefaultRetryOptions())
+	if err != nil {
+		return nil, err
+	}
+	encoder, err := encoderFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	if value := cfg.Option("labels", ""); value != "" {
+		labels = strings.Split(value, "|")
+	}
+
+	return NewLokiSink(LokiOptions{
+		Endpoint: cfg.Option("endpoint", ""),
+		Tenant:   cfg.Option("tenant", ""),
+		Labels:   labels,
+		Encoding: cfg.Option("encoding", LokiEncodingSnappy),
+		Encoder:  encoder,
+		Headers:  headers,
+		Timeout:  timeout,
+		Retry:    retry,
+	})
+}
+
+// WriteBatch pushes entries in a single request, retrying on throttling and server errors
+func (s *LokiSink) WriteBatch(ctx context.Context, entries []any) error {
+	streams, err := s.streams(entries)
+	if err != nil {
+		return err
+	}
+	if len(streams) == 0 {
+		return nil
+	}
+
+	body, contentType, contentEncoding, err := s.encode(streams)
+	if err != nil {
+		return err
+	}
+
+	return s.opts.Retry.Do(ctx, func() error {
+		req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", contentType)
+		if contentEncoding != "" {
+			req.Header.Set("Content-Encoding", contentEncoding)
+		}
+		if s.opts.Tenant != "" {
+			req.Header.Set("X-Scope-OrgID", s.opts.Tenant)
+		}
+		for key, value := range s.opts.Headers {
+			req.Header.Set(key, value)
+		}
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return retryable(fmt.Errorf("failed to push to Loki: %w", err), 0)
+		}
+		defer resp.Body.Close()
+
+		return checkHTTPResponse(resp)
+	})
+}
+
+// streams groups entries by their labels, ordering each stream's lines by timestamp
+func (s *LokiSink) streams(entries []any) ([]*lokiStream, error) {
+	var streams []*lokiStream
+	byLabels := make(map[string]*lokiStream)
+	now := time.Now()
+
+	for _, entry := range entries {
+		labels, err := s.labels(entry)
+		if err != nil {
+			return nil, err
+		}
+		var line bytes.Buffer
+		if err := s.opts.Encoder.Encode(&line, entry); err != nil {
+			return nil, err
+		}
+
+		timestamp := now
+		if metadata, ok := types.MetadataOf(entry); ok && !metadata.Timestamp.IsZero() {
+			timestamp = metadata.Timestamp
+		}
+
+		key := formatLokiLabels(labels)
+		stream, exists := byLabels[key]
+		if !exists {
+			stream = &lokiStream{labels: labels}
+			byLabels[key] = stream
+			streams = append(streams, stream)
+		}
+		stream.entries = append(stream.entries, lokiEntry{timestamp: timestamp, line: line.String()})
+	}
+
+	for _, stream := range streams {
+		sort.SliceStable(stream.entries, func(i, j int) bool {
+			return stream.entries[i].timestamp.Before(stream.entries[j].timestamp)
+		})
+	}
+
+	return streams, nil
+}
+
+// labels returns the stream labels of an entry. The resource type and namespace come from
+// the entry's metadata, so they are kept when the entry is wrapped in an envelope; extra
+// labels come from the fields of the (unwrapped) entry. Fields that are missing, empty or
+// not scalar values are left out.
+func (s *LokiSink) labels(entry any) (map[string]string, error) {
+	labels := make(map[string]string)
+	if metadata, ok := types.MetadataOf(entry); ok {
+		if metadata.ResourceType != "" {
+			labels["resource_type"] = metadata.ResourceType
+		}
+		if metadata.Namespace != "" {
+			labels["namespace"] = metadata.Namespace
+		}
+	}
+	if len(s.opts.Labels) == 0 {
+		return labels, nil
+	}
+
+	fields, err := entryFields(unwrapEntry(entry))
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range s.opts.Labels {
+		var value string
+		switch v := fields[field].(type) {
+		case string:
+			value = v
+		case json.Number:
+			value = v.String()
+		case bool:
+			value = strconv.FormatBool(v)
+		}
+		if value != "" {
+			labels[lokiLabelName(field)] = value
+		}
+	}
+	return labels, nil
+}
+
+// encode serializes streams for the configured encoding
+func (s *LokiSink) encode(streams []*lokiStream) (body []byte, contentType, contentEncoding string, err error) {
+	if s.opts.Encoding == LokiEncodingSnappy {
+		return snappy.Encode(nil, appendLokiPushRequest(nil, streams)), "application/x-protobuf", "", nil
+	}
+
+	type jsonStream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	request := struct {
+		Streams []jsonStream `json:"streams"`
+	}{}
+	for _, stream := range streams {
+		js := jsonStream{Stream: stream.labels}
+		for _, entry := range stream.entries {
+			js.Values = append(js.Values, [2]string{strconv.FormatInt(entry.timestamp.UnixNano(), 10), entry.line})
+		}
+		request.Streams = append(request.Streams, js)
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to marshal Loki push request: %w", err)
+	}
+	if s.opts.Encoding == LokiEncodingNone {
+		return data, "application/json", "", nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, "", "", err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, "", "", err
+	}
+	return buf.Bytes(), "application/json", "gzip", nil
+}
+
+// appendLokiPushRequest encodes streams as a logproto PushRequest
+func appendLokiPushRequest(b []byte, streams []*lokiStream) []byte {
+	for _, stream := range streams {
+		var streamAdapter []byte
+		streamAdapter = appendString(streamAdapter, 1, formatLokiLabels(stream.labels))
+		for _, entry := range stream.entries {
+			var timestamp []byte
+			timestamp = protowire.AppendTag(timestamp, 1, protowire.VarintType)
+			timestamp = protowire.AppendVarint(timestamp, uint64(entry.timestamp.Unix()))
+			timestamp = protowire.AppendTag(timestamp, 2, protowire.VarintType)
+			timestamp = protowire.AppendVarint(timestamp, uint64(entry.timestamp.Nanosecond()))
+
+			var entryAdapter []byte
+			entryAdapter = appendMessage(entryAdapter, 1, timestamp)
+			entryAdapter = appendString(entryAdapter, 2, entry.line)
+			streamAdapter = appendMessage(streamAdapter, 2, entryAdapter)
+		}
+		b = appendMessage(b, 1, streamAdapter)
+	}
+	return b
+}
+
+// appendString encodes a string field, omitting empty strings as proto3 does
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// appendMessage encodes an embedded message field
+func appendMessage(b []byte, num protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+// formatLokiLabels renders labels in Prometheus selector syntax with sorted names
+func formatLokiLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+"="+strconv.Quote(labels[name]))
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// lokiLabelName converts an entry field name such as "createdByKind" into a valid
+// snake_case label name such as "created_by_kind"
+func lokiLabelName(field string) string {
+	var name strings.Builder
+	for i, r := range field {
+		switch {
+		case unicode.IsUpper(r):
+			if i > 0 {
+				name.WriteByte('_')
+			}
+			name.WriteRune(unicode.ToLower(r))
+		case r == '_' || (r < unicode.MaxASCII && (unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)))):
+			name.WriteRune(r)
+		default:
+			name.WriteByte('_')
+		}
+	}
+	return name.String()
+}
@@ -0,0 +1,160 @@
+package sinks
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+func TestLokiSink_WriteBatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+	}{
+		{name: "snappy protobuf", encoding: LokiEncodingSnappy},
+		{name: "gzip json", encoding: LokiEncodingGzip},
+		{name: "plain json", encoding: LokiEncodingNone},
+	}
+
+	entries := []any{
+		&types.PodData{LogEntryMetadata: types.LogEntryMetadata{
+			Timestamp: time.Unix(200, 0), ResourceType: "pod", Name: "pod-b", Namespace: "default", CreatedByKind: "ReplicaSet",
+		}},
+		&types.PodData{LogEntryMetadata: types.LogEntryMetadata{
+			Timestamp: time.Unix(100, 0), ResourceType: "pod", Name: "pod-a", Namespace: "default", CreatedByKind: "ReplicaSet",
+		}},
+		&types.NodeData{LogEntryMetadata: types.LogEntryMetadata{
+			Timestamp: time.Unix(100, 0), ResourceType: "node", Name: "node-a",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int
+			var streams map[string][]string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if r.URL.Path != lokiPushPath || r.Header.Get("X-Scope-OrgID") != "team-a" {
+					http.Error(w, "unexpected request", http.StatusBadRequest)
+					return
+				}
+				if attempts == 1 {
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				streams = decodeLokiPush(t, r)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			sink, err := NewLokiSink(LokiOptions{
+				Endpoint: server.URL,
+				Tenant:   "team-a",
+				Labels:   []string{"createdByKind"},
+				Encoding: tt.encoding,
+				Timeout:  5 * time.Second,
+				Retry:    RetryOptions{MaxRetries: 2, InitialBackoff: time.Millisecond},
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if err := sink.WriteBatch(context.Background(), entries); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if attempts != 2 {
+				t.Errorf("Expected 2 attempts including one retry, got %d", attempts)
+			}
+
+			podStream := `{created_by_kind="ReplicaSet", namespace="default", resource_type="pod"}`
+			nodeStream := `{resource_type="node"}`
+			if len(streams) != 2 || len(streams[podStream]) != 2 || len(streams[nodeStream]) != 1 {
+				t.Fatalf("Expected pod and node streams, got %v", streams)
+			}
+			if !strings.Contains(streams[podStream][0], `"name":"pod-a"`) {
+				t.Errorf("Expected lines ordered by timestamp, got %v", streams[podStream])
+			}
+		})
+	}
+}
+
+// decodeLokiPush returns the lines of a push request keyed by stream labels
+func decodeLokiPush(t *testing.T, r *http.Request) map[string][]string {
+	t.Helper()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatalf("Failed to read request: %v", err)
+	}
+
+	streams := make(map[string][]string)
+
+	if r.Header.Get("Content-Type") == "application/x-protobuf" {
+		decoded, err := snappy.Decode(nil, body)
+		if err != nil {
+			t.Fatalf("Expected snappy body, got %v", err)
+		}
+		for _, stream := range protoFields(decoded, 1) {
+			labels := string(protoFields(stream, 1)[0])
+			for _, entry := range protoFields(stream, 2) {
+				streams[labels] = append(streams[labels], string(protoFields(entry, 2)[0]))
+			}
+		}
+		return streams
+	}
+
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(strings.NewReader(string(body)))
+		if err != nil {
+			t.Fatalf("Expected gzip body, got %v", err)
+		}
+		if body, err = io.ReadAll(gz); err != nil {
+			t.Fatalf("Failed to decompress request: %v", err)
+		}
+	}
+
+	var request struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		t.Fatalf("Expected JSON body, got %v", err)
+	}
+	for _, stream := range request.Streams {
+		labels := formatLokiLabels(stream.Stream)
+		for _, value := range stream.Values {
+			streams[labels] = append(streams[labels], value[1])
+		}
+	}
+	return streams
+}
+
+// protoFields returns the values of the length-delimited fields with the given number
+func protoFields(b []byte, field protowire.Number) [][]byte {
+	var values [][]byte
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		b = b[n:]
+		if num == field && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(b)
+			values = append(values, v)
+			b = b[n:]
+			continue
+		}
+		b = b[protowire.ConsumeFieldValue(num, typ, b):]
+	}
+	return values
+}
@@ -0,0 +1,66 @@
+package sinks
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+)
+
+// parsePairs parses a |-separated list of "key:value" pairs, as used for headers
+func parsePairs(list string) (map[string]string, error) {
+	parsed := make(map[string]string)
+	if list == "" {
+		return parsed, nil
+	}
+
+	for _, pair := range strings.Split(list, "|") {
+		key, value, ok := strings.Cut(pair, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid entry '%s', expected key:value", pair)
+		}
+		parsed[key] = strings.TrimSpace(value)
+	}
+	return parsed, nil
+}
+
+// tlsConfigFromOptions builds a TLS client configuration from a sink's tls-ca-file,
+// tls-cert-file, tls-key-file, tls-server-name and tls-insecure-skip-verify options
+func tlsConfigFromOptions(cfg config.SinkConfig) (*tls.Config, error) {
+	insecureSkipVerify, err := cfg.BoolOption("tls-insecure-skip-verify", false)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.Option("tls-server-name", ""),
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+
+	if caFile := cfg.Option("tls-ca-file", ""); caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", caFile)
+		}
+	}
+
+	certFile, keyFile := cfg.Option("tls-cert-file", ""), cfg.Option("tls-key-file", "")
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
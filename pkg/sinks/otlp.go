@@ -0,0 +1,434 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog/v2"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// OTLP transport protocols
+const (
+	OTLPProtocolGRPC = "grpc"
+	OTLPProtocolHTTP = "http/protobuf"
+)
+
+const (
+	otlpScopeName   = "kube-state-logs"
+	otlpHTTPPath    = "/v1/logs"
+	otlpServiceName = "kube-state-logs"
+)
+
+// otlpNameAttributes maps resource types and owner kinds to the k8s semantic convention
+// attribute that holds the object's name
+var otlpNameAttributes = map[string]string{
+	"pod":            "k8s.pod.name",
+	"container":      "k8s.container.name",
+	"init_container": "k8s.container.name",
+	"deployment":     "k8s.deployment.name",
+	"replicaset":     "k8s.replicaset.name",
+	"statefulset":    "k8s.statefulset.name",
+	"daemonset":      "k8s.daemonset.name",
+	"job":            "k8s.job.name",
+	"cronjob":        "k8s.cronjob.name",
+	"node":           "k8s.node.name",
+	"namespace":      "k8s.namespace.name",
+}
+
+// otlpMetadataFields are the entry fields sent as attributes instead of in the body
+var otlpMetadataFields = []string{
+	"timestamp", "resourceType", "name", "namespace", "createdByKind", "createdByName",
+	"eventType", "deleted", "deletedTimestamp",
+}
+
+// gRPC status codes that the OTLP specification considers retryable
+var otlpRetryableGRPCCodes = map[codes.Code]bool{
+	codes.Canceled:          true,
+	codes.DeadlineExceeded:  true,
+	codes.ResourceExhausted: true,
+	codes.Aborted:           true,
+	codes.OutOfRange:        true,
+	codes.Unavailable:       true,
+	codes.DataLoss:          true,
+}
+
+// OTLPOptions configures an OTLPExporter
+type OTLPOptions struct {
+	Endpoint    string            // e.g. "otel-collector:4317" for gRPC or "http://otel-collector:4318" for HTTP
+	Protocol    string            // OTLPProtocolGRPC or OTLPProtocolHTTP
+	Insecure    bool              // Use plaintext when the endpoint has no scheme
+	Headers     map[string]string // Extra request headers, e.g. for authentication
+	Timeout     time.Duration     // Timeout for a single export request
+	ServiceName string            // service.name resource attribute
+	ClusterName string            // k8s.cluster.name resource attribute, if set
+	Retry       RetryOptions
+}
+
+// OTLPExporter sends entries to an OpenTelemetry collector as OTLP log records over
+// gRPC or HTTP. Entry metadata becomes resource and log attributes following the k8s
+// semantic conventions; the remaining resource-specific fields become the record body.
+type OTLPExporter struct {
+	opts OTLPOptions
+
+	// OTLP/HTTP
+	url    string
+	client *http.Client
+
+	// OTLP/gRPC
+	conn    *grpc.ClientConn
+	service collogspb.LogsServiceClient
+}
+
+// NewOTLPExporter creates an OTLPExporter
+func NewOTLPExporter(opts OTLPOptions) (*OTLPExporter, error) {
+	if opts.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+	if opts.ServiceName == "" {
+		opts.ServiceName = otlpServiceName
+	}
+
+	exporter := &OTLPExporter{opts: opts}
+
+	switch opts.Protocol {
+	case OTLPProtocolHTTP:
+		endpoint, err := otlpEndpointURL(opts.Endpoint, opts.Insecure)
+		if err != nil {
+			return nil, err
+		}
+		if endpoint.Path == "" || endpoint.Path == "/" {
+			endpoint.Path = otlpHTTPPath
+		}
+		exporter.url = endpoint.String()
+		exporter.client = &http.Client{Timeout: opts.Timeout}
+	case OTLPProtocolGRPC:
+		endpoint, err := otlpEndpointURL(opts.Endpoint, opts.Insecure)
+		if err != nil {
+			return nil, err
+		}
+		transport := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+		if endpoint.Scheme == "http" {
+			transport = insecure.NewCredentials()
+		}
+		conn, err := grpc.NewClient(endpoint.Host, grpc.WithTransportCredentials(transport))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gRPC client for '%s': %w", opts.Endpoint, err)
+		}
+		exporter.conn = conn
+		exporter.service = collogspb.NewLogsServiceClient(conn)
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol '%s'", opts.Protocol)
+	}
+
+	return exporter, nil
+}
+
+// newOTLPExporter creates an OTLPExporter from a sink's endpoint, protocol, insecure,
+// headers, timeout, service-name, cluster-name and retry options
+func newOTLPExporter(cfg config.SinkConfig) (*OTLPExporter, error) {
+	insecure, err := cfg.BoolOption("insecure", false)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := cfg.DurationOption("timeout", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := parsePairs(cfg.Option("headers", ""))
+	if err != nil {
+		return nil, err
+	}
+	retry, err := RetryOptionsFromConfig(cfg, DefaultRetryOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOTLPExporter(OTLPOptions{
+		Endpoint:    cfg.Option("endpoint", ""),
+		Protocol:    cfg.Option("protocol", OTLPProtocolGRPC),
+		Insecure:    insecure,
+		Headers:     headers,
+		Timeout:     timeout,
+		ServiceName: cfg.Option("service-name", otlpServiceName),
+		ClusterName: cfg.Option("cluster-name", ""),
+		Retry:       retry,
+	})
+}
+
+// WriteBatch exports entries as a single OTLP request, retrying transient failures
+func (e *OTLPExporter) WriteBatch(ctx context.Context, entries []any) error {
+	resourceLogs, err := e.resourceLogs(entries, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(resourceLogs) == 0 {
+		return nil
+	}
+
+	request := &collogspb.ExportLogsServiceRequest{ResourceLogs: resourceLogs}
+
+	return e.opts.Retry.Do(ctx, func() error {
+		if e.opts.Protocol == OTLPProtocolGRPC {
+			return e.exportGRPC(request)
+		}
+		return e.exportHTTP(request)
+	})
+}
+
+// Close closes the gRPC connection, if any
+func (e *OTLPExporter) Close() error {
+	if e.conn == nil {
+		return nil
+	}
+	return e.conn.Close()
+}
+
+// resourceLogs converts entries into log records grouped by resource
+func (e *OTLPExporter) resourceLogs(entries []any, observed time.Time) ([]*logspb.ResourceLogs, error) {
+	var resourceLogs []*logspb.ResourceLogs
+	indexes := make(map[string]int)
+
+	for _, entry := range entries {
+		metadata, ok := types.MetadataOf(entry)
+		if !ok {
+			klog.Warningf("Skipping entry of type %T without metadata", entry)
+			continue
+		}
+
+		fields, err := entryFields(entry)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range otlpMetadataFields {
+			delete(fields, field)
+		}
+
+		resource := e.resourceAttributes(metadata)
+		record := &logspb.LogRecord{
+			TimeUnixNano:         uint64(metadata.Timestamp.UnixNano()),
+			ObservedTimeUnixNano: uint64(observed.UnixNano()),
+			SeverityNumber:       logspb.SeverityNumber_SEVERITY_NUMBER_INFO,
+			SeverityText:         "INFO",
+			Body:                 otlpAnyValue(fields),
+			Attributes:           logAttributes(metadata),
+		}
+
+		key := fmt.Sprint(resource)
+		index, exists := indexes[key]
+		if !exists {
+			index = len(resourceLogs)
+			indexes[key] = index
+			resourceLogs = append(resourceLogs, &logspb.ResourceLogs{
+				Resource:  &resourcepb.Resource{Attributes: resource},
+				ScopeLogs: []*logspb.ScopeLogs{{Scope: &commonpb.InstrumentationScope{Name: otlpScopeName}}},
+			})
+		}
+		scopeLogs := resourceLogs[index].ScopeLogs[0]
+		scopeLogs.LogRecords = append(scopeLogs.LogRecords, record)
+	}
+
+	return resourceLogs, nil
+}
+
+// resourceAttributes describes the Kubernetes object an entry is about
+func (e *OTLPExporter) resourceAttributes(metadata types.LogEntryMetadata) []*commonpb.KeyValue {
+	attributes := []*commonpb.KeyValue{otlpKeyValue("service.name", e.opts.ServiceName)}
+	seen := map[string]bool{"service.name": true}
+	add := func(key, value string) {
+		if key != "" && value != "" && !seen[key] {
+			seen[key] = true
+			attributes = append(attributes, otlpKeyValue(key, value))
+		}
+	}
+
+	add("k8s.cluster.name", e.opts.ClusterName)
+	add("k8s.namespace.name", metadata.Namespace)
+	add(otlpNameAttributes[metadata.ResourceType], metadata.Name)
+	add(otlpNameAttributes[strings.ToLower(metadata.CreatedByKind)], metadata.CreatedByName)
+
+	return attributes
+}
+
+// logAttributes describes the entry itself
+func logAttributes(metadata types.LogEntryMetadata) []*commonpb.KeyValue {
+	attributes := []*commonpb.KeyValue{
+		otlpKeyValue("kube_state_logs.resource_type", metadata.ResourceType),
+		otlpKeyValue("kube_state_logs.name", metadata.Name),
+	}
+	if metadata.CreatedByKind != "" {
+		attributes = append(attributes,
+			otlpKeyValue("kube_state_logs.owner.kind", metadata.CreatedByKind),
+			otlpKeyValue("kube_state_logs.owner.name", metadata.CreatedByName),
+		)
+	}
+	if metadata.EventType != "" {
+		attributes = append(attributes, otlpKeyValue("kube_state_logs.event_type", metadata.EventType))
+	}
+	if metadata.Deleted {
+		attributes = append(attributes, otlpKeyValue("kube_state_logs.deleted", true))
+	}
+	return attributes
+}
+
+// otlpKeyValue creates an OTLP attribute
+func otlpKeyValue(key string, value any) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: otlpAnyValue(value)}
+}
+
+// otlpAnyValue converts a decoded JSON value to an OTLP AnyValue. Maps are converted with
+// sorted keys so the output is deterministic; nil converts to an empty value.
+func otlpAnyValue(value any) *commonpb.AnyValue {
+	switch v := value.(type) {
+	case nil:
+		return &commonpb.AnyValue{}
+	case string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v}}
+	case int64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v}}
+	case float64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v}}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return otlpAnyValue(i)
+		}
+		if f, err := v.Float64(); err == nil {
+			return otlpAnyValue(f)
+		}
+		return otlpAnyValue(v.String())
+	case []any:
+		array := &commonpb.ArrayValue{Values: make([]*commonpb.AnyValue, len(v))}
+		for i, item := range v {
+			array.Values[i] = otlpAnyValue(item)
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: array}}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		list := &commonpb.KeyValueList{Values: make([]*commonpb.KeyValue, len(keys))}
+		for i, key := range keys {
+			list.Values[i] = otlpKeyValue(key, v[key])
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: list}}
+	default:
+		return otlpAnyValue(fmt.Sprint(v))
+	}
+}
+
+// exportHTTP sends an export request with OTLP/HTTP
+func (e *OTLPExporter) exportHTTP(request *collogspb.ExportLogsServiceRequest) error {
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal OTLP request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for key, value := range e.opts.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return retryable(fmt.Errorf("failed to send OTLP request: %w", err), 0)
+	}
+	defer resp.Body.Close()
+
+	if err := checkHTTPResponse(resp); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read OTLP response: %w", err)
+	}
+	response := &collogspb.ExportLogsServiceResponse{}
+	if err := proto.Unmarshal(data, response); err != nil {
+		return fmt.Errorf("failed to parse OTLP response: %w", err)
+	}
+	checkPartialSuccess(response)
+	return nil
+}
+
+// exportGRPC sends an export request to the OTLP LogsService
+func (e *OTLPExporter) exportGRPC(request *collogspb.ExportLogsServiceRequest) error {
+	ctx := context.Background()
+	if e.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.opts.Timeout)
+		defer cancel()
+	}
+	if len(e.opts.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(e.opts.Headers))
+	}
+
+	response, err := e.service.Export(ctx, request)
+	if err != nil {
+		err := fmt.Errorf("OTLP export failed: %w", err)
+		if otlpRetryableGRPCCodes[status.Code(err)] {
+			return retryable(err, 0)
+		}
+		return err
+	}
+	checkPartialSuccess(response)
+	return nil
+}
+
+// checkPartialSuccess logs records the collector rejected. They are not retried.
+func checkPartialSuccess(response *collogspb.ExportLogsServiceResponse) {
+	partialSuccess := response.GetPartialSuccess()
+	if partialSuccess.GetRejectedLogRecords() > 0 || partialSuccess.GetErrorMessage() != "" {
+		klog.Warningf("OTLP collector rejected %d log records: %s", partialSuccess.GetRejectedLogRecords(), partialSuccess.GetErrorMessage())
+	}
+}
+
+// otlpEndpointURL parses an endpoint that may omit its scheme
+func otlpEndpointURL(endpoint string, insecure bool) (*url.URL, error) {
+	if !strings.Contains(endpoint, "://") {
+		scheme := "https"
+		if insecure {
+			scheme = "http"
+		}
+		endpoint = scheme + "://" + endpoint
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint '%s': %w", endpoint, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme '%s' in endpoint '%s'", parsed.Scheme, endpoint)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,214 @@
+package sinks
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// otlpReceiver is a stand-in for an OTLP collector that fails a configurable number
+// of requests before accepting them
+type otlpReceiver struct {
+	collogspb.UnimplementedLogsServiceServer
+
+	mu       sync.Mutex
+	failures int
+	requests []*collogspb.ExportLogsServiceRequest
+	headers  []string
+}
+
+func (r *otlpReceiver) record(request *collogspb.ExportLogsServiceRequest, header string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, request)
+	r.headers = append(r.headers, header)
+	return len(r.requests) > r.failures
+}
+
+func (r *otlpReceiver) handleHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	request := &collogspb.ExportLogsServiceRequest{}
+	if req.URL.Path != otlpHTTPPath || req.Header.Get("Content-Type") != "application/x-protobuf" || proto.Unmarshal(body, request) != nil {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+	if !r.record(request, req.Header.Get("X-Tenant")) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+}
+
+// Export implements the OTLP LogsService
+func (r *otlpReceiver) Export(ctx context.Context, request *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var header string
+	if values := md.Get("x-tenant"); len(values) > 0 {
+		header = values[0]
+	}
+	if !r.record(request, header) {
+		return nil, status.Error(codes.Unavailable, "collector is starting")
+	}
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+// startOTLPReceiver serves the receiver with the given protocol and returns its endpoint
+func startOTLPReceiver(t *testing.T, receiver *otlpReceiver, protocol string) string {
+	t.Helper()
+
+	if protocol == OTLPProtocolHTTP {
+		server := httptest.NewServer(http.HandlerFunc(receiver.handleHTTP))
+		t.Cleanup(server.Close)
+		return server.URL
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	server := grpc.NewServer()
+	collogspb.RegisterLogsServiceServer(server, receiver)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return "http://" + listener.Addr().String()
+}
+
+// stringAttributes returns the string attributes of a list of key values
+func stringAttributes(attributes []*commonpb.KeyValue) map[string]string {
+	values := make(map[string]string, len(attributes))
+	for _, kv := range attributes {
+		values[kv.GetKey()] = kv.GetValue().GetStringValue()
+	}
+	return values
+}
+
+func TestOTLPExporter_WriteBatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+	}{
+		{name: "http", protocol: OTLPProtocolHTTP},
+		{name: "grpc", protocol: OTLPProtocolGRPC},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := &otlpReceiver{failures: 1}
+			endpoint := startOTLPReceiver(t, receiver, tt.protocol)
+
+			exporter, err := NewOTLPExporter(OTLPOptions{
+				Endpoint:    endpoint,
+				Protocol:    tt.protocol,
+				Headers:     map[string]string{"X-Tenant": "team-a"},
+				Timeout:     5 * time.Second,
+				ClusterName: "test-cluster",
+				Retry:       RetryOptions{MaxRetries: 2, InitialBackoff: time.Millisecond},
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer exporter.Close()
+
+			entries := []any{
+				&types.PodData{
+					LogEntryMetadata: types.LogEntryMetadata{
+						Timestamp:     time.Now(),
+						ResourceType:  "pod",
+						Name:          "test-pod",
+						Namespace:     "default",
+						CreatedByKind: "ReplicaSet",
+						CreatedByName: "test-rs",
+					},
+					NodeName: "test-node",
+				},
+			}
+
+			if err := exporter.WriteBatch(context.Background(), entries); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if len(receiver.requests) != 2 {
+				t.Fatalf("Expected 2 requests including one retry, got %d", len(receiver.requests))
+			}
+			if receiver.headers[1] != "team-a" {
+				t.Errorf("Expected the X-Tenant header 'team-a', got '%s'", receiver.headers[1])
+			}
+
+			request := receiver.requests[1]
+			if len(request.GetResourceLogs()) != 1 || len(request.GetResourceLogs()[0].GetScopeLogs()) != 1 {
+				t.Fatalf("Expected one resource with one scope, got %v", request)
+			}
+			resourceLogs := request.GetResourceLogs()[0]
+			scopeLogs := resourceLogs.GetScopeLogs()[0]
+			if scopeLogs.GetScope().GetName() != otlpScopeName || len(scopeLogs.GetLogRecords()) != 1 {
+				t.Fatalf("Expected one record in scope %s, got %v", otlpScopeName, scopeLogs)
+			}
+
+			resource := stringAttributes(resourceLogs.GetResource().GetAttributes())
+			for key, expected := range map[string]string{
+				"service.name":        "kube-state-logs",
+				"k8s.cluster.name":    "test-cluster",
+				"k8s.namespace.name":  "default",
+				"k8s.pod.name":        "test-pod",
+				"k8s.replicaset.name": "test-rs",
+			} {
+				if resource[key] != expected {
+					t.Errorf("Expected resource attribute %s=%s, got '%s'", key, expected, resource[key])
+				}
+			}
+
+			record := scopeLogs.GetLogRecords()[0]
+			if value := stringAttributes(record.GetAttributes())["kube_state_logs.resource_type"]; value != "pod" {
+				t.Errorf("Expected resource type attribute 'pod', got '%s'", value)
+			}
+			body := stringAttributes(record.GetBody().GetKvlistValue().GetValues())
+			if body["nodeName"] != "test-node" {
+				t.Errorf("Expected nodeName 'test-node' in the body, got '%s'", body["nodeName"])
+			}
+			if _, exists := body["resourceType"]; exists {
+				t.Errorf("Expected metadata fields to be attributes, not part of the body")
+			}
+		})
+	}
+}
+
+func TestOTLPExporter_NonRetryableError(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	exporter, err := NewOTLPExporter(OTLPOptions{
+		Endpoint: server.URL,
+		Protocol: OTLPProtocolHTTP,
+		Retry:    RetryOptions{MaxRetries: 3, InitialBackoff: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entry := &types.DeploymentData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "deployment", Name: "test"}}
+	if err := exporter.WriteBatch(context.Background(), []any{entry}); err == nil {
+		t.Error("Expected error for rejected request")
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request without retries, got %d", requests)
+	}
+}
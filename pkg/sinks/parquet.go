@@ -0,0 +1,462 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+	"k8s.io/klog/v2"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// Parquet compression codecs
+const (
+	ParquetCompressionSnappy = "snappy"
+	ParquetCompressionGzip   = "gzip"
+	ParquetCompressionNone   = "none"
+)
+
+// parquetTmpSuffix marks files that are still being written
+const parquetTmpSuffix = ".tmp"
+
+// ParquetOptions configures a ParquetSink
+type ParquetOptions struct {
+	Dir          string        // Directory holding a subdirectory of files per resource type
+	RollInterval time.Duration // Maximum time a file stays open
+	MaxFileSize  int64         // Size in bytes after which a file is closed
+	RowGroupSize int           // Number of rows buffered before they are written as a row group
+	Compression  string        // ParquetCompressionSnappy, ParquetCompressionGzip or ParquetCompressionNone
+}
+
+// ParquetSink writes entries to Parquet files, one file at a time per resource type, with
+// a schema derived from the entry type. Files are written under a temporary name and
+// renamed to "<dir>/<resourceType>/<resourceType>-<time>.parquet" once they are complete.
+type ParquetSink struct {
+	opts  ParquetOptions
+	codec parquet.CompressionCodec
+
+	mu    sync.Mutex
+	files map[string]*parquetFile
+}
+
+// parquetColumn is a top-level field of an entry type stored as an optional column
+type parquetColumn struct {
+	name  string
+	index []int  // Field index for reflect.Value.FieldByIndex
+	tag   string // Type of the column in the schema metadata of the writer
+	value func(reflect.Value) (any, error)
+}
+
+// parquetFile is a Parquet file being written
+type parquetFile struct {
+	path      string
+	file      *os.File
+	writer    *writer.CSVWriter
+	entryType reflect.Type
+	columns   []parquetColumn
+	openedAt  time.Time
+	rows      int // Rows buffered for the next row group
+}
+
+// parquetColumnCache holds the columns of each entry type
+var parquetColumnCache sync.Map
+
+// NewParquetSink creates a ParquetSink
+func NewParquetSink(opts ParquetOptions) (*ParquetSink, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("dir is required")
+	}
+	if opts.RowGroupSize <= 0 {
+		return nil, fmt.Errorf("row group size must be positive, got %d", opts.RowGroupSize)
+	}
+
+	var codec parquet.CompressionCodec
+	switch opts.Compression {
+	case ParquetCompressionSnappy:
+		codec = parquet.CompressionCodec_SNAPPY
+	case ParquetCompressionGzip:
+		codec = parquet.CompressionCodec_GZIP
+	case ParquetCompressionNone:
+		codec = parquet.CompressionCodec_UNCOMPRESSED
+	default:
+		return nil, fmt.Errorf("unknown parquet compression '%s'", opts.Compression)
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", opts.Dir, err)
+	}
+	if err := removeIncompleteParquetFiles(opts.Dir); err != nil {
+		return nil, err
+	}
+
+	return &ParquetSink{
+		opts:  opts,
+		codec: codec,
+		files: make(map[string]*parquetFile),
+	}, nil
+}
+
+// removeIncompleteParquetFiles removes the temporary files left by a previous run that
+// stopped before completing them. Without a footer, they cannot be read.
+func removeIncompleteParquetFiles(dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to look for incomplete parquet files: %w", err)
+		}
+		if entry.IsDir() || !strings.HasSuffix(path, ".parquet"+parquetTmpSuffix) {
+			return nil
+		}
+		klog.Warningf("Removing incomplete parquet file %s", path)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove incomplete parquet file: %w", err)
+		}
+		return nil
+	})
+}
+
+// newParquetSink creates a ParquetSink from a sink's dir, roll-interval, max-size-mb,
+// row-group-size and compression options
+func newParquetSink(cfg config.SinkConfig) (*ParquetSink, error) {
+	// Columns come from the entry types, so entries cannot be flattened or wrapped
+	if format := cfg.Option("output-format", OutputFormatJSON); format != OutputFormatJSON {
+		return nil, fmt.Errorf("output format '%s' is not supported by sink type '%s'", format, cfg.Type)
+	}
+	if envelope := cfg.Option("envelope", EnvelopeNone); envelope != EnvelopeNone {
+		return nil, fmt.Errorf("envelope '%s' is not supported by sink type '%s'", envelope, cfg.Type)
+	}
+
+	rollInterval, err := cfg.DurationOption("roll-interval", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	maxSizeMB, err := cfg.IntOption("max-size-mb", 128)
+	if err != nil {
+		return nil, err
+	}
+	rowGroupSize, err := cfg.IntOption("row-group-size", 10000)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewParquetSink(ParquetOptions{
+		Dir:          cfg.Option("dir", ""),
+		RollInterval: rollInterval,
+		MaxFileSize:  int64(maxSizeMB) * 1024 * 1024,
+		RowGroupSize: rowGroupSize,
+		Compression:  cfg.Option("compression", ParquetCompressionSnappy),
+	})
+}
+
+// WriteBatch adds entries to the file of their resource type, then completes files that
+// reached their size or age limit. Entries that are not structs are skipped and reported
+// in the returned error.
+func (s *ParquetSink) WriteBatch(ctx context.Context, entries []any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, entry := range entries {
+		if err := s.write(entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for resourceType, file := range s.files {
+		if file.size() < s.opts.MaxFileSize && time.Since(file.openedAt) < s.opts.RollInterval {
+			continue
+		}
+		if err := file.close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(s.files, resourceType)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close completes all open files
+func (s *ParquetSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for resourceType, file := range s.files {
+		if err := file.close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(s.files, resourceType)
+	}
+	return errors.Join(errs...)
+}
+
+// write adds an entry to the file of its resource type, opening a new file if needed
+func (s *ParquetSink) write(entry any) error {
+	value := reflect.ValueOf(entry)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot write entry of type %T as parquet", entry)
+	}
+
+	metadata, _ := types.MetadataOf(entry)
+	resourceType := metadata.ResourceType
+	if resourceType == "" {
+		resourceType = "unknown"
+	}
+
+	file := s.files[resourceType]
+	if file != nil && file.entryType != value.Type() {
+		// Rows of another type need another schema
+		if err := file.close(); err != nil {
+			return err
+		}
+		file = nil
+	}
+	if file == nil {
+		var err error
+		if file, err = s.open(resourceType, value.Type()); err != nil {
+			return err
+		}
+		s.files[resourceType] = file
+	}
+
+	if err := file.append(value); err != nil {
+		return fmt.Errorf("failed to write entry of type %T: %w", entry, err)
+	}
+	if file.rows >= s.opts.RowGroupSize {
+		return file.flush()
+	}
+	return nil
+}
+
+// open creates a new file for a resource type
+func (s *ParquetSink) open(resourceType string, entryType reflect.Type) (*parquetFile, error) {
+	columns, err := parquetColumns(entryType)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(s.opts.Dir, resourceType)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	now := time.Now()
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.parquet", resourceType, now.UTC().Format("20060102T150405.000000000Z")))
+	file, err := os.OpenFile(path+parquetTmpSuffix, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parquet file: %w", err)
+	}
+
+	metadata := make([]string, len(columns))
+	for i, column := range columns {
+		metadata[i] = fmt.Sprintf("name=%s, %s, repetitiontype=OPTIONAL", column.name, column.tag)
+	}
+	w, err := writer.NewCSVWriterFromWriter(metadata, file, 1)
+	if err != nil {
+		file.Close()
+		os.Remove(path + parquetTmpSuffix)
+		return nil, fmt.Errorf("failed to create parquet writer: %w", err)
+	}
+	w.CompressionType = s.codec
+	// Row groups are written by number of rows rather than by size
+	w.RowGroupSize = math.MaxInt64
+
+	return &parquetFile{
+		path:      path,
+		file:      file,
+		writer:    w,
+		entryType: entryType,
+		columns:   columns,
+		openedAt:  now,
+	}, nil
+}
+
+// append writes the column values of a row
+func (f *parquetFile) append(row reflect.Value) error {
+	values := make([]any, len(f.columns))
+	for i, column := range f.columns {
+		value, err := column.value(row.FieldByIndex(column.index))
+		if err != nil {
+			return fmt.Errorf("field '%s': %w", column.name, err)
+		}
+		values[i] = value
+	}
+
+	if err := f.writer.Write(values); err != nil {
+		return err
+	}
+	f.rows++
+	return nil
+}
+
+// flush writes the buffered rows as a row group
+func (f *parquetFile) flush() error {
+	if f.rows == 0 {
+		return nil
+	}
+	if err := f.writer.Flush(true); err != nil {
+		return fmt.Errorf("failed to write parquet file: %w", err)
+	}
+	f.rows = 0
+	return nil
+}
+
+// size returns the number of bytes written to the file, excluding buffered rows
+func (f *parquetFile) size() int64 {
+	return f.writer.Offset
+}
+
+// close writes the remaining rows and the footer, and renames the file into place
+func (f *parquetFile) close() error {
+	err := f.writer.WriteStop()
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.path+parquetTmpSuffix, f.path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to complete parquet file %s: %w", f.path, err)
+	}
+
+	klog.V(2).Infof("Completed parquet file %s with %d row groups", f.path, len(f.writer.Footer.RowGroups))
+	return nil
+}
+
+// parquetColumns returns the columns of an entry type: its JSON fields in declaration
+// order, including those of embedded structs such as types.LogEntryMetadata. Scalars and
+// timestamps get their own Parquet types; maps, slices and structs are stored as JSON.
+func parquetColumns(entryType reflect.Type) ([]parquetColumn, error) {
+	if columns, ok := parquetColumnCache.Load(entryType); ok {
+		return columns.([]parquetColumn), nil
+	}
+
+	columns := structParquetColumns(entryType, nil)
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("entry type %v has no fields", entryType)
+	}
+	parquetColumnCache.Store(entryType, columns)
+	return columns, nil
+}
+
+// structParquetColumns returns the columns of a struct type whose fields are at index
+func structParquetColumns(t reflect.Type, index []int) []parquetColumn {
+	var columns []parquetColumn
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			columns = append(columns, structParquetColumns(field.Type, fieldIndex)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		column := parquetColumnFor(field.Type)
+		column.name = name
+		column.index = fieldIndex
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// parquetColumnFor returns the Parquet type and value conversion of a Go type. Nil
+// pointers, maps and slices and zero timestamps are stored as nulls.
+func parquetColumnFor(t reflect.Type) parquetColumn {
+	if t.Kind() == reflect.Pointer {
+		column := parquetColumnFor(t.Elem())
+		elementValue := column.value
+		column.value = func(v reflect.Value) (any, error) {
+			if v.IsNil() {
+				return nil, nil
+			}
+			return elementValue(v.Elem())
+		}
+		return column
+	}
+
+	var column parquetColumn
+	switch {
+	case t == timeType:
+		column.tag = "type=INT64, convertedtype=TIMESTAMP_MILLIS"
+		column.value = func(v reflect.Value) (any, error) {
+			timestamp := v.Interface().(time.Time)
+			if timestamp.IsZero() {
+				return nil, nil
+			}
+			return timestamp.UnixMilli(), nil
+		}
+	case t.Kind() == reflect.String:
+		column.tag = "type=BYTE_ARRAY, convertedtype=UTF8"
+		column.value = func(v reflect.Value) (any, error) {
+			return v.String(), nil
+		}
+	case t.Kind() == reflect.Bool:
+		column.tag = "type=BOOLEAN"
+		column.value = func(v reflect.Value) (any, error) {
+			return v.Bool(), nil
+		}
+	case t.Kind() == reflect.Int8 || t.Kind() == reflect.Int16 || t.Kind() == reflect.Int32:
+		column.tag = "type=INT32"
+		column.value = func(v reflect.Value) (any, error) {
+			return int32(v.Int()), nil
+		}
+	case t.Kind() == reflect.Uint8 || t.Kind() == reflect.Uint16:
+		column.tag = "type=INT32"
+		column.value = func(v reflect.Value) (any, error) {
+			return int32(v.Uint()), nil
+		}
+	case t.Kind() == reflect.Int || t.Kind() == reflect.Int64:
+		column.tag = "type=INT64"
+		column.value = func(v reflect.Value) (any, error) {
+			return v.Int(), nil
+		}
+	case t.Kind() == reflect.Uint || t.Kind() == reflect.Uint32 || t.Kind() == reflect.Uint64:
+		column.tag = "type=INT64"
+		column.value = func(v reflect.Value) (any, error) {
+			return int64(v.Uint()), nil
+		}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		column.tag = "type=DOUBLE"
+		column.value = func(v reflect.Value) (any, error) {
+			return v.Float(), nil
+		}
+	default:
+		column.tag = "type=BYTE_ARRAY, convertedtype=JSON"
+		column.value = func(v reflect.Value) (any, error) {
+			if (v.Kind() == reflect.Map || v.Kind() == reflect.Slice || v.Kind() == reflect.Interface) && v.IsNil() {
+				return nil, nil
+			}
+			data, err := json.Marshal(v.Interface())
+			return string(data), err
+		}
+	}
+	return column
+}
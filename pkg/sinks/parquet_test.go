@@ -0,0 +1,197 @@
+package sinks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+func TestParquetSink_WriteBatch(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewParquetSink(ParquetOptions{Dir: dir, RollInterval: time.Hour, MaxFileSize: 1 << 20, RowGroupSize: 2, Compression: ParquetCompressionNone})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ready := true
+	pods := podBatch("web-0", "web-1", "web-2")
+	pods[0].(*types.PodData).Ready = &ready
+	pods[0].(*types.PodData).Timestamp = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	node := &types.NodeData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "node", Name: "node-1"}}
+	if err := sink.WriteBatch(context.Background(), append(pods, node)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "pod", "*.parquet")); len(files) != 0 {
+		t.Errorf("Expected no complete files before close, got %v", files)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Expected no error on close, got %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "pod", "pod-*.parquet"))
+	if len(files) != 1 {
+		t.Fatalf("Expected one pod file, got %v", files)
+	}
+	if nodeFiles, _ := filepath.Glob(filepath.Join(dir, "node", "node-*.parquet")); len(nodeFiles) != 1 {
+		t.Errorf("Expected one node file, got %v", nodeFiles)
+	}
+
+	pr := openParquetFile(t, files[0])
+	if pr.GetNumRows() != 3 {
+		t.Errorf("Expected 3 rows, got %d", pr.GetNumRows())
+	}
+	if len(pr.Footer.RowGroups) != 2 {
+		t.Errorf("Expected 2 row groups, got %d", len(pr.Footer.RowGroups))
+	}
+
+	// Schema elements after the root, with their converted types
+	columns := map[string]*parquet.SchemaElement{}
+	for i, element := range pr.SchemaHandler.SchemaElements[1:] {
+		columns[pr.SchemaHandler.Infos[i+1].ExName] = element
+	}
+	for _, name := range []string{"timestamp", "resourceType", "name", "nodeName", "ready", "conditions", "restartCount"} {
+		if _, ok := columns[name]; !ok {
+			t.Errorf("Expected a '%s' column, got %v", name, columns)
+		}
+	}
+	for name, expected := range map[string]parquet.ConvertedType{"timestamp": parquet.ConvertedType_TIMESTAMP_MILLIS, "name": parquet.ConvertedType_UTF8, "labels": parquet.ConvertedType_JSON} {
+		if element, ok := columns[name]; !ok || element.GetConvertedType() != expected {
+			t.Errorf("Expected column %s to have converted type %v, got %v", name, expected, element)
+		}
+	}
+
+	names := readParquetColumn(t, pr, "name", 3)
+	if len(names) != 3 || names[0] != "web-0" || names[1] != "web-1" || names[2] != "web-2" {
+		t.Errorf("Expected names web-0, web-1 and web-2, got %v", names)
+	}
+	readyValues := readParquetColumn(t, pr, "ready", 3)
+	if len(readyValues) != 3 || readyValues[0] != true || readyValues[1] != nil {
+		t.Errorf("Expected ready values [true <nil> <nil>], got %v", readyValues)
+	}
+	timestamps := readParquetColumn(t, pr, "timestamp", 3)
+	if len(timestamps) != 3 || timestamps[0] != int64(1714564800000) || timestamps[1] != nil {
+		t.Errorf("Expected timestamps [1714564800000 <nil> <nil>], got %v", timestamps)
+	}
+}
+
+func TestParquetSink_Roll(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewParquetSink(ParquetOptions{Dir: dir, RollInterval: time.Hour, MaxFileSize: 1, RowGroupSize: 1, Compression: ParquetCompressionSnappy})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for range 3 {
+		if err := sink.WriteBatch(context.Background(), podBatch("web-0")); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "pod", "*.parquet"))
+	if len(files) != 3 {
+		t.Fatalf("Expected a file per batch, got %v", files)
+	}
+	for _, file := range files {
+		if rows := openParquetFile(t, file).GetNumRows(); rows != 1 {
+			t.Errorf("Expected 1 row in %s, got %d", file, rows)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Expected no error on close, got %v", err)
+	}
+}
+
+func TestNewParquetSink_RemovesIncompleteFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "pod"), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	incomplete := filepath.Join(dir, "pod", "pod-20240501T120000.000000000Z.parquet.tmp")
+	complete := filepath.Join(dir, "pod", "pod-20240501T110000.000000000Z.parquet")
+	for _, path := range []string{incomplete, complete} {
+		if err := os.WriteFile(path, []byte("PAR1"), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	sink, err := NewParquetSink(ParquetOptions{Dir: dir, RollInterval: time.Hour, MaxFileSize: 1 << 20, RowGroupSize: 1, Compression: ParquetCompressionNone})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer sink.Close()
+
+	if _, err := os.Stat(incomplete); !os.IsNotExist(err) {
+		t.Errorf("Expected the incomplete file to be removed, got %v", err)
+	}
+	if _, err := os.Stat(complete); err != nil {
+		t.Errorf("Expected the complete file to be kept, got %v", err)
+	}
+}
+
+func TestNewParquetSink_Config(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     map[string]string
+		expectError bool
+	}{
+		{name: "defaults", options: map[string]string{}, expectError: false},
+		{name: "gzip", options: map[string]string{"compression": "gzip", "roll-interval": "15m"}, expectError: false},
+		{name: "no dir", options: map[string]string{"dir": ""}, expectError: true},
+		{name: "unknown compression", options: map[string]string{"compression": "zstd"}, expectError: true},
+		{name: "csv", options: map[string]string{"output-format": OutputFormatCSV}, expectError: true},
+		{name: "cloudevents", options: map[string]string{"envelope": EnvelopeCloudEvents}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := map[string]string{"dir": t.TempDir()}
+			for key, value := range tt.options {
+				options[key] = value
+			}
+			_, err := newParquetSink(config.SinkConfig{Name: "test", Type: TypeParquet, Options: options})
+			if tt.expectError && err == nil {
+				t.Errorf("Expected an error for options %v", tt.options)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+// openParquetFile opens a file with the reader of the parquet library
+func openParquetFile(t *testing.T, path string) *reader.ParquetReader {
+	t.Helper()
+	file, err := local.NewLocalFileReader(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	pr, err := reader.NewParquetColumnReader(file, 1)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	t.Cleanup(pr.ReadStop)
+	return pr
+}
+
+// readParquetColumn reads the first rows of a column, with nil for nulls
+func readParquetColumn(t *testing.T, pr *reader.ParquetReader, column string, rows int64) []any {
+	t.Helper()
+	values, _, _, err := pr.ReadColumnByPath(common.ReformPathStr("parquet_go_root."+column), rows)
+	if err != nil {
+		t.Fatalf("Failed to read column %s: %v", column, err)
+	}
+	return values
+}
@@ -0,0 +1,181 @@
+package sinks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+)
+
+// RetryOptions configures how failed sends are retried with exponential backoff
+type RetryOptions struct {
+	MaxRetries     int           // Number of retries after the first attempt
+	InitialBackoff time.Duration // Wait before the first retry
+	MaxBackoff     time.Duration // Upper bound for the wait between retries
+	MaxElapsed     time.Duration // Total time budget for all attempts (0 is unlimited)
+}
+
+// DefaultRetryOptions returns the retry options used when a sink does not configure them
+func DefaultRetryOptions() RetryOptions {
+	return RetryOptions{
+		MaxRetries:     5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+	}
+}
+
+// RetryOptionsFromConfig reads the max-retries, retry-initial-backoff, retry-max-backoff
+// and retry-max-elapsed options of a sink, falling back to the given defaults
+func RetryOptionsFromConfig(cfg config.SinkConfig, defaults RetryOptions) (RetryOptions, error) {
+	var err error
+	opts := defaults
+
+	if opts.MaxRetries, err = cfg.IntOption("max-retries", defaults.MaxRetries); err != nil {
+		return opts, err
+	}
+	if opts.InitialBackoff, err = cfg.DurationOption("retry-initial-backoff", defaults.InitialBackoff); err != nil {
+		return opts, err
+	}
+	if opts.MaxBackoff, err = cfg.DurationOption("retry-max-backoff", defaults.MaxBackoff); err != nil {
+		return opts, err
+	}
+	if opts.MaxElapsed, err = cfg.DurationOption("retry-max-elapsed", defaults.MaxElapsed); err != nil {
+		return opts, err
+	}
+
+	return opts, nil
+}
+
+// retryableError marks an error as transient. A positive after overrides the backoff,
+// e.g. from a Retry-After header.
+type retryableError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// retryable wraps an error so that RetryOptions.Do retries the operation
+func retryable(err error, after time.Duration) error {
+	return &retryableError{err: err, after: after}
+}
+
+// isRetryable reports whether an error is transient, including errors returned by
+// RetryOptions.Do after the retries are exhausted
+func isRetryable(err error) bool {
+	var retryErr *retryableError
+	return errors.As(err, &retryErr)
+}
+
+// partialWriteError is returned by writers that sent part of a batch before failing, so
+// that only the unsent entries are written again
+type partialWriteError struct {
+	err    error
+	unsent []any
+}
+
+func (e *partialWriteError) Error() string {
+	return fmt.Sprintf("%d entries were not sent: %v", len(e.unsent), e.err)
+}
+
+func (e *partialWriteError) Unwrap() error {
+	return e.err
+}
+
+// partialWrite wraps the error of a batch of which only the unsent entries failed
+func partialWrite(err error, unsent []any) error {
+	return &partialWriteError{err: err, unsent: unsent}
+}
+
+// unsentEntries returns the entries of a batch that were not sent when writing it failed
+// with err, which are all of them unless the writer reported a partial write
+func unsentEntries(err error, entries []any) []any {
+	var partialErr *partialWriteError
+	if errors.As(err, &partialErr) {
+		return partialErr.unsent
+	}
+	return entries
+}
+
+// Do runs operation until it succeeds, returns an error that is not retryable,
+// or the retries or time budget are exhausted. It stops waiting to retry when ctx is done,
+// returning the last error, which stays retryable.
+func (o RetryOptions) Do(ctx context.Context, operation func() error) error {
+	backoff := o.InitialBackoff
+	start := time.Now()
+
+	for attempt := 0; ; attempt++ {
+		err := operation()
+		if err == nil {
+			return nil
+		}
+
+		var retryErr *retryableError
+		if !errors.As(err, &retryErr) {
+			return err
+		}
+		if attempt >= o.MaxRetries || ctx.Err() != nil {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+		}
+
+		wait := backoff
+		if retryErr.after > 0 {
+			wait = retryErr.after
+		}
+		if o.MaxBackoff > 0 && wait > o.MaxBackoff {
+			wait = o.MaxBackoff
+		}
+		if o.MaxElapsed > 0 && time.Since(start)+wait > o.MaxElapsed {
+			return fmt.Errorf("giving up after %d attempts in %v: %w", attempt+1, time.Since(start).Round(time.Millisecond), err)
+		}
+		klog.V(2).Infof("Retrying in %v after error: %v", wait, err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+		case <-timer.C:
+		}
+
+		backoff *= 2
+	}
+}
+
+// checkHTTPResponse returns an error for unsuccessful responses. Throttling and server
+// errors are retryable and honour the Retry-After header.
+func checkHTTPResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err := fmt.Errorf("unexpected status %s: %s", resp.Status, body)
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return retryable(err, retryAfter(resp))
+	}
+	return err
+}
+
+// retryAfter returns the delay requested by a Retry-After header in seconds, if any
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,279 @@
+package sinks
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// rotatedTimeFormat is appended to the path of rotated files. It sorts chronologically.
+const rotatedTimeFormat = "20060102T150405.000000000"
+
+// RotationOptions configures when a RotatingFile rotates and which rotated files it keeps
+type RotationOptions struct {
+	MaxSize  int64         // Rotate before a write would exceed this many bytes (0 disables)
+	MaxAge   time.Duration // Rotate once the current file is older than this (0 disables)
+	MaxFiles int           // Number of rotated files to keep (0 keeps all)
+	Compress bool          // Gzip rotated files
+}
+
+// RotatingFile is an io.WriteCloser that appends to a file and rotates it by size and age.
+// Rotated files are renamed to "<path>.<timestamp>" and optionally gzipped to
+// "<path>.<timestamp>.gz"; both steps use renames, so readers never see partial files.
+type RotatingFile struct {
+	path string
+	opts RotationOptions
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	// compressWg tracks background compression of rotated files
+	compressWg sync.WaitGroup
+}
+
+// NewRotatingFile opens or creates the file at path for appending
+func NewRotatingFile(path string, opts RotationOptions) (*RotatingFile, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	if opts.MaxSize < 0 || opts.MaxAge < 0 || opts.MaxFiles < 0 {
+		return nil, fmt.Errorf("rotation options must not be negative")
+	}
+
+	f := &RotatingFile{
+		path: path,
+		opts: opts,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p to the current file, rotating first if p would exceed the size limit
+// or the file has reached its maximum age. p is never split across files.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, ErrSinkClosed
+	}
+
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file and waits for rotated files to be compressed
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+
+	f.compressWg.Wait()
+	return err
+}
+
+// open opens the file at the configured path, continuing from its current size
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", f.path, err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat %s: %w", f.path, err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	if f.size > 0 {
+		// A file left by a previous run is as old as the last rotation, or at least as its last write
+		f.openedAt = info.ModTime()
+		if timestamps, _ := f.rotatedFiles(); len(timestamps) > 0 {
+			if rotatedAt, err := time.Parse(rotatedTimeFormat, timestamps[0]); err == nil {
+				f.openedAt = rotatedAt
+			}
+		}
+	}
+	return nil
+}
+
+// Size returns the size of the current file
+func (f *RotatingFile) Size() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.size
+}
+
+// RotateIfNeeded rotates the file if writing n bytes would exceed the rotation limits,
+// reporting whether it did. Writers that start every file with a header call it before
+// encoding a write.
+func (f *RotatingFile) RotateIfNeeded(n int64) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return false, ErrSinkClosed
+	}
+	if !f.shouldRotate(n) {
+		return false, nil
+	}
+	return true, f.rotate()
+}
+
+// shouldRotate reports whether the file must be rotated before writing n bytes
+func (f *RotatingFile) shouldRotate(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.opts.MaxSize > 0 && f.size+n > f.opts.MaxSize {
+		return true
+	}
+	return f.opts.MaxAge > 0 && time.Since(f.openedAt) >= f.opts.MaxAge
+}
+
+// rotate renames the current file aside, opens a new one and prunes old rotated files
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", f.path, err)
+	}
+	f.file = nil
+
+	rotated := f.path + "." + time.Now().UTC().Format(rotatedTimeFormat)
+	if err := os.Rename(f.path, rotated); err != nil {
+		// Keep writing to the current file rather than losing entries
+		if openErr := f.open(); openErr != nil {
+			return errors.Join(err, openErr)
+		}
+		return fmt.Errorf("failed to rotate %s: %w", f.path, err)
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	if f.opts.Compress {
+		f.compressWg.Add(1)
+		go func() {
+			defer f.compressWg.Done()
+			if err := compressFile(rotated); err != nil {
+				klog.Errorf("Failed to compress %s: %v", rotated, err)
+			}
+			f.prune()
+		}()
+		return nil
+	}
+
+	f.prune()
+	return nil
+}
+
+// prune removes the oldest rotated files beyond MaxFiles
+func (f *RotatingFile) prune() {
+	if f.opts.MaxFiles == 0 {
+		return
+	}
+
+	timestamps, byTimestamp := f.rotatedFiles()
+	for i := f.opts.MaxFiles; i < len(timestamps); i++ {
+		for _, path := range byTimestamp[timestamps[i]] {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				klog.Errorf("Failed to remove rotated file %s: %v", path, err)
+			}
+		}
+	}
+}
+
+// rotatedFiles returns the timestamps of rotated files, newest first, and the paths of
+// the files with each timestamp
+func (f *RotatingFile) rotatedFiles() ([]string, map[string][]string) {
+	matches, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		klog.Errorf("Failed to list rotated files for %s: %v", f.path, err)
+		return nil, nil
+	}
+
+	// Uncompressed and compressed copies of the same file count once; temporary files not at all
+	byTimestamp := make(map[string][]string)
+	for _, match := range matches {
+		if strings.HasSuffix(match, ".tmp") {
+			continue
+		}
+		timestamp := strings.TrimSuffix(strings.TrimPrefix(match, f.path+"."), ".gz")
+		if _, err := time.Parse(rotatedTimeFormat, timestamp); err != nil {
+			continue
+		}
+		byTimestamp[timestamp] = append(byTimestamp[timestamp], match)
+	}
+
+	timestamps := make([]string, 0, len(byTimestamp))
+	for timestamp := range byTimestamp {
+		timestamps = append(timestamps, timestamp)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(timestamps)))
+
+	return timestamps, byTimestamp
+}
+
+// compressFile gzips path to path.gz through a temporary file and removes the original
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,189 @@
+package sinks
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile_Write(t *testing.T) {
+	tests := []struct {
+		name             string
+		opts             RotationOptions
+		writes           int
+		expectedRotated  int
+		expectCompressed bool
+	}{
+		{
+			name:            "rotates by size",
+			opts:            RotationOptions{MaxSize: 20},
+			writes:          4,
+			expectedRotated: 3,
+		},
+		{
+			name:            "keeps max files",
+			opts:            RotationOptions{MaxSize: 20, MaxFiles: 2},
+			writes:          6,
+			expectedRotated: 2,
+		},
+		{
+			name:             "compresses rotated files",
+			opts:             RotationOptions{MaxSize: 20, MaxFiles: 2, Compress: true},
+			writes:           6,
+			expectedRotated:  2,
+			expectCompressed: true,
+		},
+		{
+			name:            "does not rotate without limits",
+			opts:            RotationOptions{},
+			writes:          4,
+			expectedRotated: 0,
+		},
+	}
+
+	line := []byte(`{"name":"test-pod"}` + "\n")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "kube-state-logs.log")
+
+			file, err := NewRotatingFile(path, tt.opts)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for i := 0; i < tt.writes; i++ {
+				if _, err := file.Write(line); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+			if err := file.Close(); err != nil {
+				t.Fatalf("Expected no error on close, got %v", err)
+			}
+
+			current, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Expected current file to exist, got %v", err)
+			}
+			if tt.opts.MaxSize > 0 && string(current) != string(line) {
+				t.Errorf("Expected current file to hold the last line, got %q", current)
+			}
+
+			rotated, err := filepath.Glob(path + ".*")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(rotated) != tt.expectedRotated {
+				t.Fatalf("Expected %d rotated files, got %d: %v", tt.expectedRotated, len(rotated), rotated)
+			}
+
+			for _, rotatedPath := range rotated {
+				if strings.HasSuffix(rotatedPath, ".gz") != tt.expectCompressed {
+					t.Errorf("Expected compressed=%v for %s", tt.expectCompressed, rotatedPath)
+				}
+				if tt.expectCompressed {
+					assertGzipContent(t, rotatedPath, string(line))
+				}
+			}
+		})
+	}
+}
+
+func assertGzipContent(t *testing.T, path, expected string) {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("Expected valid gzip file, got %v", err)
+	}
+	content, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(content) != expected {
+		t.Errorf("Expected content %q, got %q", expected, content)
+	}
+}
+
+func TestRotatingFile_MaxAgeAcrossRestarts(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		modifiedAgo     time.Duration
+		lastRotationAgo time.Duration // 0 leaves no rotated file
+		expectedRotated int
+	}{
+		{
+			name:            "file last written before max age",
+			content:         "{}\n",
+			modifiedAgo:     2 * time.Hour,
+			expectedRotated: 1,
+		},
+		{
+			name:            "recently written file rotated before max age",
+			content:         "{}\n",
+			modifiedAgo:     time.Minute,
+			lastRotationAgo: 2 * time.Hour,
+			expectedRotated: 2,
+		},
+		{
+			name:            "recent file",
+			content:         "{}\n",
+			modifiedAgo:     time.Minute,
+			lastRotationAgo: 10 * time.Minute,
+			expectedRotated: 1,
+		},
+		{
+			name:            "empty file",
+			modifiedAgo:     2 * time.Hour,
+			expectedRotated: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "kube-state-logs.log")
+			if tt.lastRotationAgo > 0 {
+				rotated := path + "." + time.Now().Add(-tt.lastRotationAgo).UTC().Format(rotatedTimeFormat)
+				if err := os.WriteFile(rotated, []byte("{}\n"), 0o644); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			modified := time.Now().Add(-tt.modifiedAgo)
+			if err := os.Chtimes(path, modified, modified); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			file, err := NewRotatingFile(path, RotationOptions{MaxAge: time.Hour})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if _, err := file.Write([]byte("{}\n")); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := file.Close(); err != nil {
+				t.Fatalf("Expected no error on close, got %v", err)
+			}
+
+			rotated, err := filepath.Glob(path + ".*")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(rotated) != tt.expectedRotated {
+				t.Errorf("Expected %d rotated files, got %d: %v", tt.expectedRotated, len(rotated), rotated)
+			}
+		})
+	}
+}
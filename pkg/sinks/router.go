@@ -0,0 +1,76 @@
+package sinks
+
+import (
+	"context"
+	"errors"
+
+	"go.goms.io/aks/kube-state-logs/pkg/interfaces"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// Router fans entries out to sinks based on the entry's resource type
+type Router struct {
+	routes   map[string][]interfaces.Logger
+	defaults []interfaces.Logger
+	sinks    []interfaces.Logger
+}
+
+// NewRouter creates an empty Router
+func NewRouter() *Router {
+	return &Router{
+		routes: make(map[string][]interfaces.Logger),
+	}
+}
+
+// AddSink adds a sink that receives the given resource types. A sink without resource
+// types receives every resource type that is not explicitly routed to another sink.
+func (r *Router) AddSink(sink interfaces.Logger, resourceTypes []string) {
+	r.sinks = append(r.sinks, sink)
+
+	if len(resourceTypes) == 0 {
+		r.defaults = append(r.defaults, sink)
+		return
+	}
+
+	for _, resourceType := range resourceTypes {
+		r.routes[resourceType] = append(r.routes[resourceType], sink)
+	}
+}
+
+// Log writes the entry to every sink it is routed to
+func (r *Router) Log(entry any) error {
+	return r.LogContext(context.Background(), entry)
+}
+
+// LogContext writes the entry to every sink it is routed to, giving up on sinks that
+// wait for room once ctx is done
+func (r *Router) LogContext(ctx context.Context, entry any) error {
+	var errs []error
+	for _, sink := range r.sinksFor(entry) {
+		var err error
+		if contextSink, ok := sink.(interfaces.ContextLogger); ok {
+			err = contextSink.LogContext(ctx, entry)
+		} else {
+			err = sink.Log(entry)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes all sinks
+func (r *Router) Close() error {
+	return closeAll(r.sinks)
+}
+
+// sinksFor returns the sinks an entry should be written to
+func (r *Router) sinksFor(entry any) []interfaces.Logger {
+	if metadata, ok := types.MetadataOf(entry); ok {
+		if sinks, exists := r.routes[metadata.ResourceType]; exists {
+			return sinks
+		}
+	}
+	return r.defaults
+}
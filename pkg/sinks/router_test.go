@@ -0,0 +1,59 @@
+package sinks
+
+import (
+	"testing"
+
+	"go.goms.io/aks/kube-state-logs/pkg/collector/testutils"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+func TestRouter_Log(t *testing.T) {
+	workloads := &testutils.MockLogger{}
+	security := &testutils.MockLogger{}
+	audit := &testutils.MockLogger{}
+
+	router := NewRouter()
+	router.AddSink(workloads, nil)
+	router.AddSink(security, []string{"role", "clusterrole"})
+	router.AddSink(audit, []string{"clusterrole"})
+
+	entries := []any{
+		types.DeploymentData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "deployment"}},
+		types.RoleData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "role"}},
+		&types.ClusterRoleData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "clusterrole"}},
+		types.ContainerData{ResourceType: "container"},
+	}
+	for _, entry := range entries {
+		if err := router.Log(entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		sink          *testutils.MockLogger
+		expectedTypes []string
+	}{
+		{name: "default sink receives unrouted types", sink: workloads, expectedTypes: []string{"deployment", "container"}},
+		{name: "routed sink receives its types", sink: security, expectedTypes: []string{"role", "clusterrole"}},
+		{name: "resource types can be routed to several sinks", sink: audit, expectedTypes: []string{"clusterrole"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := tt.sink.GetLogs()
+			if len(logs) != len(tt.expectedTypes) {
+				t.Fatalf("Expected %d entries, got %d", len(tt.expectedTypes), len(logs))
+			}
+			for i, log := range logs {
+				metadata, ok := types.MetadataOf(log)
+				if !ok {
+					t.Fatalf("Expected entry with metadata, got %T", log)
+				}
+				if metadata.ResourceType != tt.expectedTypes[i] {
+					t.Errorf("Expected resource type '%s', got '%s'", tt.expectedTypes[i], metadata.ResourceType)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package sinks
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/interfaces"
+)
+
+// Sink types
+const (
+	TypeStdout        = "stdout"
+	TypeFile          = "file"
+	TypeRotatingFile  = "rotating-file"
+	TypeOTLP          = "otlp"
+	TypeLoki          = "loki"
+	TypeElasticsearch = "elasticsearch"
+	TypeSplunk        = "splunk"
+	TypeSyslog        = "syslog"
+	TypeWebhook       = "webhook"
+	TypeAzureMonitor  = "azure-monitor"
+	TypeParquet       = "parquet"
+)
+
+// New creates a sink from its configuration
+func New(cfg config.SinkConfig) (interfaces.Logger, error) {
+	encoder, err := encoderFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Type {
+	case TypeStdout:
+		sink := NewStdoutSink()
+		sink.SetEncoder(encoder)
+		return newBatchingSink(cfg, sink)
+	case TypeFile:
+		sink, err := NewFileSink(cfg.Option("path", ""))
+		if err != nil {
+			return nil, err
+		}
+		sink.SetEncoder(encoder)
+		return newBatchingSink(cfg, sink)
+	case TypeRotatingFile:
+		sink, err := newRotatingFileSink(cfg)
+		if err != nil {
+			return nil, err
+		}
+		sink.SetEncoder(encoder)
+		return newBatchingSink(cfg, sink)
+	case TypeOTLP:
+		exporter, err := newOTLPExporter(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newBatchingSink(cfg, exporter)
+	case TypeLoki:
+		sink, err := newLokiSink(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newBatchingSink(cfg, sink)
+	case TypeElasticsearch:
+		sink, err := newElasticsearchSink(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newBatchingSink(cfg, sink)
+	case TypeSplunk:
+		sink, err := newSplunkSink(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newBatchingSink(cfg, sink)
+	case TypeSyslog:
+		sink, err := newSyslogSink(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newBatchingSink(cfg, sink)
+	case TypeWebhook:
+		sink, err := newWebhookSink(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newBatchingSink(cfg, sink)
+	case TypeAzureMonitor:
+		sink, err := newAzureMonitorSink(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newBatchingSink(cfg, sink)
+	case TypeParquet:
+		sink, err := newParquetSink(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newBatchingSink(cfg, sink)
+	default:
+		return nil, fmt.Errorf("unknown type '%s' for sink '%s'", cfg.Type, cfg.Name)
+	}
+}
+
+// newRotatingFileSink creates a WriterSink over a RotatingFile configured from the sink's
+// path, max-size-mb, max-age, max-files and compress options
+func newRotatingFileSink(cfg config.SinkConfig) (*WriterSink, error) {
+	maxSizeMB, err := cfg.IntOption("max-size-mb", 100)
+	if err != nil {
+		return nil, err
+	}
+	maxAge, err := cfg.DurationOption("max-age", 0)
+	if err != nil {
+		return nil, err
+	}
+	maxFiles, err := cfg.IntOption("max-files", 5)
+	if err != nil {
+		return nil, err
+	}
+	compress, err := cfg.BoolOption("compress", false)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := NewRotatingFile(cfg.Option("path", ""), RotationOptions{
+		MaxSize:  int64(maxSizeMB) * 1024 * 1024,
+		MaxAge:   maxAge,
+		MaxFiles: maxFiles,
+		Compress: compress,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWriterSink(file), nil
+}
+
+// newBatchingSink wraps a writer in a BatchingSink configured from the sink's options,
+// with flattening, the configured envelope and disk spool in between. Entries are wrapped
+// before they are spooled, so replayed events keep their IDs. The writer is closed if the
+// options are invalid.
+func newBatchingSink(cfg config.SinkConfig, writer BatchWriter) (interfaces.Logger, error) {
+	opts, err := BatchOptionsFromConfig(cfg, DefaultBatchOptions())
+	if err != nil {
+		closeWriter(writer)
+		return nil, err
+	}
+
+	spooled, err := spoolFromConfig(cfg, writer)
+	if err != nil {
+		closeWriter(writer)
+		return nil, err
+	}
+
+	enveloped, err := envelopeFromConfig(cfg, spooled)
+	if err != nil {
+		closeWriter(spooled)
+		return nil, err
+	}
+
+	flattened := flattenFromConfig(cfg, enveloped)
+
+	sink, err := NewBatchingSink(flattened, opts)
+	if err != nil {
+		closeWriter(flattened)
+		return nil, err
+	}
+
+	return sink, nil
+}
+
+// NewFromConfig creates every configured sink and a Router that sends entries to them.
+// Without any sink configuration, all entries are written to stdout.
+func NewFromConfig(sinkConfigs []config.SinkConfig) (*Router, error) {
+	if len(sinkConfigs) == 0 {
+		sinkConfigs = []config.SinkConfig{{Name: TypeStdout, Type: TypeStdout}}
+	}
+
+	router := NewRouter()
+	for _, sinkConfig := range sinkConfigs {
+		sink, err := New(sinkConfig)
+		if err != nil {
+			router.Close()
+			return nil, fmt.Errorf("failed to create sink '%s': %w", sinkConfig.Name, err)
+		}
+		router.AddSink(sink, sinkConfig.Resources)
+	}
+
+	return router, nil
+}
+
+// closeAll closes every sink that holds resources, returning all errors encountered
+func closeAll(sinks []interfaces.Logger) error {
+	var errs []error
+	for _, sink := range sinks {
+		if closer, ok := sink.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeWriter closes a writer that holds resources, ignoring errors
+func closeWriter(writer any) {
+	if closer, ok := writer.(io.Closer); ok {
+		closer.Close()
+	}
+}
@@ -0,0 +1,276 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+const (
+	splunkEventPath = "/services/collector/event"
+	splunkAckPath   = "/services/collector/ack"
+)
+
+// SplunkOptions configures a SplunkSink
+type SplunkOptions struct {
+	Endpoint         string            // e.g. "https://splunk-hec.example.com:8088"
+	Token            string            // HEC token
+	Host             string            // Event host, usually the cluster name
+	Source           string            // Event source
+	SourcetypePrefix string            // Prefix of the sourcetype, followed by the resource type
+	Index            string            // Default index, or the token's default index if empty
+	Indexes          map[string]string // Index per resource type
+	Ack              bool              // Wait for indexer acknowledgement of each batch
+	AckTimeout       time.Duration     // How long to wait for an acknowledgement before resending
+	AckPollInterval  time.Duration     // How often to poll for acknowledgements
+	TLS              *tls.Config       // TLS client configuration
+	Timeout          time.Duration     // Timeout for a single request
+	Retry            RetryOptions
+}
+
+// SplunkSink sends entries to a Splunk HTTP Event Collector. Each entry becomes an event
+// whose sourcetype is derived from its resource type.
+type SplunkSink struct {
+	opts    SplunkOptions
+	url     string
+	ackURL  string
+	channel string
+	client  *http.Client
+}
+
+// splunkEvent is the HEC event envelope
+type splunkEvent struct {
+	Time       float64 `json:"time"`
+	Host       string  `json:"host,omitempty"`
+	Source     string  `json:"source,omitempty"`
+	Sourcetype string  `json:"sourcetype,omitempty"`
+	Index      string  `json:"index,omitempty"`
+	Event      any     `json:"event"`
+}
+
+// NewSplunkSink creates a SplunkSink
+func NewSplunkSink(opts SplunkOptions) (*SplunkSink, error) {
+	if opts.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+	if opts.Token == "" {
+		return nil, fmt.Errorf("token is required")
+	}
+
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint '%s': %w", opts.Endpoint, err)
+	}
+	base := strings.TrimSuffix(endpoint.Path, "/")
+
+	endpoint.Path = base + splunkEventPath
+	eventURL := endpoint.String()
+	endpoint.Path = base + splunkAckPath
+	ackURL := endpoint.String()
+
+	return &SplunkSink{
+		opts:    opts,
+		url:     eventURL,
+		ackURL:  ackURL,
+		channel: uuid.NewString(),
+		client: &http.Client{
+			Timeout:   opts.Timeout,
+			Transport: &http.Transport{TLSClientConfig: opts.TLS, Proxy: http.ProxyFromEnvironment},
+		},
+	}, nil
+}
+
+// newSplunkSink creates a SplunkSink from a sink's endpoint, token, host, source,
+// sourcetype-prefix, index, indexes, ack, TLS, timeout and retry options
+func newSplunkSink(cfg config.SinkConfig) (*SplunkSink, error) {
+	indexes, err := parsePairs(cfg.Option("indexes", ""))
+	if err != nil {
+		return nil, err
+	}
+	ack, err := cfg.BoolOption("ack", false)
+	if err != nil {
+		return nil, err
+	}
+	ackTimeout, err := cfg.DurationOption("ack-timeout", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	ackPollInterval, err := cfg.DurationOption("ack-poll-interval", time.Second)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := tlsConfigFromOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := cfg.DurationOption("timeout", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	retry, err := RetryOptionsFromConfig(cfg, DefaultRetryOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	token := cfg.Option("token", "")
+	if tokenFile := cfg.Option("token-file", ""); tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	return NewSplunkSink(SplunkOptions{
+		Endpoint:         cfg.Option("endpoint", ""),
+		Token:            token,
+		Host:             cfg.Option("host", cfg.Option("cluster-name", "")),
+		Source:           cfg.Option("source", "kube-state-logs"),
+		SourcetypePrefix: cfg.Option("sourcetype-prefix", "kube-state-logs:"),
+		Index:            cfg.Option("index", ""),
+		Indexes:          indexes,
+		Ack:              ack,
+		AckTimeout:       ackTimeout,
+		AckPollInterval:  ackPollInterval,
+		TLS:              tlsConfig,
+		Timeout:          timeout,
+		Retry:            retry,
+	})
+}
+
+// WriteBatch sends entries as HEC events in a single request. With acknowledgements
+// enabled, a batch that is not acknowledged in time is sent again.
+func (s *SplunkSink) WriteBatch(ctx context.Context, entries []any) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	now := time.Now()
+
+	for _, entry := range entries {
+		metadata, _ := types.MetadataOf(entry)
+		timestamp := metadata.Timestamp
+		if timestamp.IsZero() {
+			timestamp = now
+		}
+
+		event := splunkEvent{
+			Time:       float64(timestamp.UnixMilli()) / 1000,
+			Host:       s.opts.Host,
+			Source:     s.opts.Source,
+			Sourcetype: s.opts.SourcetypePrefix + metadata.ResourceType,
+			Index:      s.opts.Index,
+			Event:      entry,
+		}
+		if index, exists := s.opts.Indexes[metadata.ResourceType]; exists {
+			event.Index = index
+		}
+
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("failed to marshal entry of type %T: %w", entry, err)
+		}
+	}
+	if body.Len() == 0 {
+		return nil
+	}
+
+	return s.opts.Retry.Do(ctx, func() error {
+		ackID, err := s.send(body.Bytes())
+		if err != nil {
+			return err
+		}
+		if s.opts.Ack {
+			return s.waitForAck(ackID)
+		}
+		return nil
+	})
+}
+
+// send posts events to the collector and returns the acknowledgement ID, if any
+func (s *SplunkSink) send(body []byte) (int64, error) {
+	var response struct {
+		Text  string `json:"text"`
+		Code  int    `json:"code"`
+		AckID *int64 `json:"ackId"`
+	}
+	if err := s.post(s.url, body, &response); err != nil {
+		return 0, err
+	}
+	if response.Code != 0 {
+		return 0, fmt.Errorf("HEC rejected events with code %d: %s", response.Code, response.Text)
+	}
+	if s.opts.Ack && response.AckID == nil {
+		return 0, fmt.Errorf("HEC response has no ackId; is indexer acknowledgement enabled for the token?")
+	}
+	if response.AckID == nil {
+		return 0, nil
+	}
+	return *response.AckID, nil
+}
+
+// waitForAck polls the collector until the batch is acknowledged or the timeout expires
+func (s *SplunkSink) waitForAck(ackID int64) error {
+	request, err := json.Marshal(map[string][]int64{"acks": {ackID}})
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(s.opts.AckTimeout)
+	for {
+		var response struct {
+			Acks map[string]bool `json:"acks"`
+		}
+		if err := s.post(s.ackURL, request, &response); err != nil {
+			return err
+		}
+		if response.Acks[strconv.FormatInt(ackID, 10)] {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return retryable(fmt.Errorf("batch with ackId %d was not acknowledged within %v", ackID, s.opts.AckTimeout), 0)
+		}
+		time.Sleep(s.opts.AckPollInterval)
+	}
+}
+
+// post sends a request on the sink's channel and decodes the JSON response
+func (s *SplunkSink) post(target string, body []byte, response any) error {
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Splunk "+s.opts.Token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Splunk-Request-Channel", s.channel)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return retryable(fmt.Errorf("failed to send request to HEC: %w", err), 0)
+	}
+	defer resp.Body.Close()
+
+	if err := checkHTTPResponse(resp); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return retryable(fmt.Errorf("failed to read HEC response: %w", err), 0)
+	}
+	if err := json.Unmarshal(data, response); err != nil {
+		return fmt.Errorf("failed to parse HEC response: %w", err)
+	}
+	return nil
+}
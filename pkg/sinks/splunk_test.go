@@ -0,0 +1,110 @@
+package sinks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+func TestSplunkSink_WriteBatch(t *testing.T) {
+	var events []splunkEvent
+	var channels []string
+	ackPolls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Splunk test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		channels = append(channels, r.Header.Get("X-Splunk-Request-Channel"))
+
+		switch r.URL.Path {
+		case splunkEventPath:
+			scanner := bufio.NewScanner(r.Body)
+			for scanner.Scan() {
+				var event splunkEvent
+				if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				events = append(events, event)
+			}
+			fmt.Fprint(w, `{"text":"Success","code":0,"ackId":7}`)
+		case splunkAckPath:
+			ackPolls++
+			// The first poll reports the batch as not yet indexed
+			fmt.Fprintf(w, `{"acks":{"7":%v}}`, ackPolls > 1)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	sink, err := NewSplunkSink(SplunkOptions{
+		Endpoint:         server.URL,
+		Token:            "test-token",
+		Host:             "test-cluster",
+		Source:           "kube-state-logs",
+		SourcetypePrefix: "kube-state-logs:",
+		Index:            "k8s",
+		Indexes:          map[string]string{"clusterrole": "security"},
+		Ack:              true,
+		AckTimeout:       5 * time.Second,
+		AckPollInterval:  time.Millisecond,
+		Timeout:          5 * time.Second,
+		Retry:            RetryOptions{MaxRetries: 1, InitialBackoff: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entries := []any{
+		&types.DeploymentData{LogEntryMetadata: types.LogEntryMetadata{Timestamp: time.Unix(1700000000, 0), ResourceType: "deployment", Name: "web"}},
+		&types.ClusterRoleData{LogEntryMetadata: types.LogEntryMetadata{Timestamp: time.Unix(1700000000, 0), ResourceType: "clusterrole", Name: "admin"}},
+	}
+	if err := sink.WriteBatch(context.Background(), entries); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if ackPolls != 2 {
+		t.Errorf("Expected 2 ack polls, got %d", ackPolls)
+	}
+	for _, channel := range channels {
+		if channel == "" || channel != channels[0] {
+			t.Errorf("Expected all requests on one channel, got %v", channels)
+			break
+		}
+	}
+
+	expected := []struct {
+		sourcetype string
+		index      string
+	}{
+		{sourcetype: "kube-state-logs:deployment", index: "k8s"},
+		{sourcetype: "kube-state-logs:clusterrole", index: "security"},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(events))
+	}
+	for i, event := range events {
+		if event.Sourcetype != expected[i].sourcetype {
+			t.Errorf("Expected sourcetype '%s', got '%s'", expected[i].sourcetype, event.Sourcetype)
+		}
+		if event.Index != expected[i].index {
+			t.Errorf("Expected index '%s', got '%s'", expected[i].index, event.Index)
+		}
+		if event.Host != "test-cluster" {
+			t.Errorf("Expected host 'test-cluster', got '%s'", event.Host)
+		}
+		if event.Time != 1700000000 {
+			t.Errorf("Expected time 1700000000, got %v", event.Time)
+		}
+	}
+}
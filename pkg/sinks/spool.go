@@ -0,0 +1,451 @@
+package sinks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/metrics"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+const spoolSegmentSuffix = ".spool"
+
+// SpoolOptions configures a Spool
+type SpoolOptions struct {
+	Name           string        // Name of the sink, for metrics
+	Dir            string        // Directory holding spooled batches, ideally on a persistent volume
+	MaxSize        int64         // Maximum size of all spooled batches in bytes; the oldest are dropped beyond it
+	ReplayInterval time.Duration // How often to try sending spooled batches
+}
+
+// Spool is a BatchWriter that keeps batches on disk while the wrapped writer is
+// unavailable and replays them in order once it recovers. Batches are only spooled
+// for transient failures; the spool survives restarts.
+type Spool struct {
+	writer BatchWriter
+	opts   SpoolOptions
+
+	// mu guards the segment list. It is not held while sending to the writer.
+	mu        sync.Mutex
+	segments  []spoolSegment
+	size      int64
+	nextSeq   uint64
+	replaying string
+
+	dropped atomic.Int64
+	ctx     context.Context // Cancelled by Close to stop replaying
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// spoolSegment is a batch stored in its own file
+type spoolSegment struct {
+	path    string
+	size    int64
+	entries int
+}
+
+// spoolRecord is a spooled entry together with its metadata, so the entry can be
+// replayed to sinks that route or label by metadata
+type spoolRecord struct {
+	Metadata types.LogEntryMetadata `json:"metadata"`
+	Entry    json.RawMessage        `json:"entry"`
+}
+
+// spooledEntry is an entry read back from the spool
+type spooledEntry struct {
+	metadata types.LogEntryMetadata
+	raw      json.RawMessage
+}
+
+// GetMetadata returns the metadata of the original entry
+func (e spooledEntry) GetMetadata() types.LogEntryMetadata {
+	return e.metadata
+}
+
+// MarshalJSON returns the original entry's JSON
+func (e spooledEntry) MarshalJSON() ([]byte, error) {
+	return e.raw, nil
+}
+
+// NewSpool creates a Spool in opts.Dir, picking up batches left by a previous run,
+// and starts replaying them in the background
+func NewSpool(writer BatchWriter, opts SpoolOptions) (*Spool, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("spool directory is required")
+	}
+	if opts.MaxSize <= 0 {
+		return nil, fmt.Errorf("spool size must be positive, got %d", opts.MaxSize)
+	}
+	if opts.ReplayInterval <= 0 {
+		return nil, fmt.Errorf("spool replay interval must be positive, got %v", opts.ReplayInterval)
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+
+	s := &Spool{
+		writer: writer,
+		opts:   opts,
+		done:   make(chan struct{}),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if len(s.segments) > 0 {
+		klog.Infof("Found %d spooled batches in %s to replay", len(s.segments), opts.Dir)
+	}
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	go s.run()
+	return s, nil
+}
+
+// spoolFromConfig wraps a writer in a Spool if the sink sets the spool-dir option,
+// configured from its spool-max-size-mb and spool-replay-interval options
+func spoolFromConfig(cfg config.SinkConfig, writer BatchWriter) (BatchWriter, error) {
+	dir := cfg.Option("spool-dir", "")
+	if dir == "" {
+		return writer, nil
+	}
+
+	maxSizeMB, err := cfg.IntOption("spool-max-size-mb", 512)
+	if err != nil {
+		return nil, err
+	}
+	replayInterval, err := cfg.DurationOption("spool-replay-interval", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSpool(writer, SpoolOptions{
+		Name:           cfg.Name,
+		Dir:            dir,
+		MaxSize:        int64(maxSizeMB) * 1024 * 1024,
+		ReplayInterval: replayInterval,
+	})
+}
+
+// WriteBatch sends entries to the writer, or spools them if earlier batches are still
+// spooled or the writer fails with a transient error
+func (s *Spool) WriteBatch(ctx context.Context, entries []any) error {
+	s.mu.Lock()
+	spooling := len(s.segments) > 0
+	s.mu.Unlock()
+
+	if !spooling {
+		err := s.writer.WriteBatch(ctx, entries)
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+		entries = unsentEntries(err, entries)
+		klog.Warningf("Sink is unavailable, spooling %d entries: %v", len(entries), err)
+	}
+
+	return s.spool(entries)
+}
+
+// Dropped returns the number of spooled entries discarded to stay within the size limit
+func (s *Spool) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Pending returns the number of entries waiting in the spool
+func (s *Spool) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := 0
+	for _, segment := range s.segments {
+		pending += segment.entries
+	}
+	return pending
+}
+
+// Close stops replaying and closes the writer. Spooled batches stay on disk for the next run.
+func (s *Spool) Close() error {
+	s.cancel()
+	<-s.done
+
+	if pending := s.Pending(); pending > 0 {
+		klog.Warningf("Leaving %d spooled entries in %s for the next run", pending, s.opts.Dir)
+	}
+
+	if closer, ok := s.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// run replays spooled batches every replay interval until the spool is closed
+func (s *Spool) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.opts.ReplayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.replay()
+		}
+	}
+}
+
+// replay sends spooled batches oldest first until the spool is empty or a send fails
+func (s *Spool) replay() {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		default:
+		}
+
+		s.mu.Lock()
+		if len(s.segments) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		segment := s.segments[0]
+		s.replaying = segment.path
+		s.mu.Unlock()
+
+		entries, err := readSpoolSegment(segment.path)
+		if err == nil {
+			err = s.writer.WriteBatch(s.ctx, entries)
+		}
+
+		s.mu.Lock()
+		s.replaying = ""
+		if err != nil && isRetryable(err) {
+			// Entries of the batch that were sent are not replayed again
+			if unsent := unsentEntries(err, entries); len(unsent) < len(entries) {
+				if rewriteErr := s.rewriteSegmentLocked(0, unsent); rewriteErr != nil {
+					klog.Errorf("Failed to remove sent entries from spooled batch %s: %v", segment.path, rewriteErr)
+				}
+			}
+			s.mu.Unlock()
+			klog.V(2).Infof("Sink is still unavailable, keeping %d spooled batches: %v", len(s.segments), err)
+			return
+		}
+		if err != nil {
+			klog.Errorf("Dropping spooled batch %s: %v", segment.path, err)
+			s.dropped.Add(int64(segment.entries))
+			metrics.SpoolDroppedEntries.WithLabelValues(s.opts.Name).Add(float64(segment.entries))
+		}
+		s.removeSegmentLocked(0)
+		remaining := len(s.segments)
+		s.mu.Unlock()
+
+		if remaining == 0 {
+			klog.Info("Replayed all spooled batches")
+		}
+	}
+}
+
+// spool writes entries to a new segment, dropping the oldest segments to make room
+func (s *Spool) spool(entries []any) error {
+	data, err := encodeSpoolSegment(entries)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	size := int64(len(data))
+	if size > s.opts.MaxSize {
+		s.dropEntries(len(entries))
+		return fmt.Errorf("batch of %d bytes exceeds the spool size limit", size)
+	}
+	for s.size+size > s.opts.MaxSize {
+		if !s.dropOldestLocked() {
+			break
+		}
+	}
+
+	path := filepath.Join(s.opts.Dir, fmt.Sprintf("%020d-%d%s", s.nextSeq, len(entries), spoolSegmentSuffix))
+	if err := writeFileAtomic(path, data); err != nil {
+		s.dropEntries(len(entries))
+		return fmt.Errorf("failed to spool entries: %w", err)
+	}
+
+	s.nextSeq++
+	s.segments = append(s.segments, spoolSegment{path: path, size: size, entries: len(entries)})
+	s.size += size
+	return nil
+}
+
+// rewriteSegmentLocked replaces the entries of a segment, keeping its place in the spool
+func (s *Spool) rewriteSegmentLocked(i int, entries []any) error {
+	data, err := encodeSpoolSegment(entries)
+	if err != nil {
+		return err
+	}
+
+	segment := s.segments[i]
+	seq, _, _ := strings.Cut(filepath.Base(segment.path), "-")
+	path := filepath.Join(s.opts.Dir, fmt.Sprintf("%s-%d%s", seq, len(entries), spoolSegmentSuffix))
+	if err := writeFileAtomic(path, data); err != nil {
+		return err
+	}
+	if path != segment.path {
+		if err := os.Remove(segment.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	s.size += int64(len(data)) - segment.size
+	s.segments[i] = spoolSegment{path: path, size: int64(len(data)), entries: len(entries)}
+	return nil
+}
+
+// dropOldestLocked removes the oldest segment that is not being replayed
+func (s *Spool) dropOldestLocked() bool {
+	for i, segment := range s.segments {
+		if segment.path == s.replaying {
+			continue
+		}
+		s.dropEntries(segment.entries)
+		s.removeSegmentLocked(i)
+		return true
+	}
+	return false
+}
+
+// dropEntries records entries discarded by the spool
+func (s *Spool) dropEntries(count int) {
+	total := s.dropped.Add(int64(count))
+	metrics.SpoolDroppedEntries.WithLabelValues(s.opts.Name).Add(float64(count))
+	klog.Warningf("Spool %s is full, dropped %d entries (%d in total)", s.opts.Dir, count, total)
+}
+
+// removeSegmentLocked deletes a segment's file and forgets it
+func (s *Spool) removeSegmentLocked(i int) {
+	segment := s.segments[i]
+	if err := os.Remove(segment.path); err != nil && !os.IsNotExist(err) {
+		klog.Errorf("Failed to remove spooled batch %s: %v", segment.path, err)
+	}
+	s.segments = append(s.segments[:i], s.segments[i+1:]...)
+	s.size -= segment.size
+}
+
+// load reads the segments left in the spool directory by a previous run
+func (s *Spool) load() error {
+	entries, err := os.ReadDir(s.opts.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read spool directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(s.opts.Dir, name)
+
+		// Temporary files are batches whose write never completed
+		if strings.HasSuffix(name, ".tmp") {
+			os.Remove(path)
+			continue
+		}
+
+		var seq uint64
+		var count int
+		if _, err := fmt.Sscanf(strings.TrimSuffix(name, spoolSegmentSuffix), "%d-%d", &seq, &count); err != nil || !strings.HasSuffix(name, spoolSegmentSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat spooled batch %s: %w", path, err)
+		}
+
+		s.segments = append(s.segments, spoolSegment{path: path, size: info.Size(), entries: count})
+		s.size += info.Size()
+		if seq >= s.nextSeq {
+			s.nextSeq = seq + 1
+		}
+	}
+
+	// Zero-padded sequence numbers sort in spool order
+	sort.Slice(s.segments, func(i, j int) bool {
+		return s.segments[i].path < s.segments[j].path
+	})
+	return nil
+}
+
+// encodeSpoolSegment encodes entries as the spool records of a segment, one per line
+func encodeSpoolSegment(entries []any) ([]byte, error) {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	for _, entry := range entries {
+		raw, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal entry of type %T: %w", entry, err)
+		}
+		metadata, _ := types.MetadataOf(entry)
+		if err := encoder.Encode(spoolRecord{Metadata: metadata, Entry: raw}); err != nil {
+			return nil, fmt.Errorf("failed to marshal entry of type %T: %w", entry, err)
+		}
+	}
+	return data.Bytes(), nil
+}
+
+// readSpoolSegment reads the entries of a spooled batch
+func readSpoolSegment(path string) ([]any, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []any
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var record spoolRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("corrupt spooled entry: %w", err)
+		}
+		entries = append(entries, spooledEntry{metadata: record.Metadata, raw: record.Entry})
+	}
+	return entries, scanner.Err()
+}
+
+// writeFileAtomic writes data to a temporary file and renames it into place, so a
+// crash never leaves a partially written file at path
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	if syncErr := file.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
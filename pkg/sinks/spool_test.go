@@ -0,0 +1,213 @@
+package sinks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"go.goms.io/aks/kube-state-logs/pkg/metrics"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// flakyWriter fails with a transient error while it is down and records what it receives.
+// While down, it sends the first partial entries of a batch before failing.
+type flakyWriter struct {
+	mu      sync.Mutex
+	down    bool
+	partial int
+	names   []string
+	types   []string
+}
+
+func (w *flakyWriter) WriteBatch(ctx context.Context, entries []any) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	sent := len(entries)
+	if w.down {
+		sent = min(w.partial, len(entries))
+	}
+	for _, entry := range entries[:sent] {
+		metadata, _ := types.MetadataOf(entry)
+		w.names = append(w.names, metadata.Name)
+		w.types = append(w.types, metadata.ResourceType)
+	}
+
+	if sent == len(entries) {
+		return nil
+	}
+	err := retryable(errors.New("connection refused"), 0)
+	if sent > 0 {
+		return partialWrite(err, entries[sent:])
+	}
+	return err
+}
+
+func (w *flakyWriter) setDown(down bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.down = down
+}
+
+func (w *flakyWriter) received() ([]string, []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.names...), append([]string(nil), w.types...)
+}
+
+func podBatch(names ...string) []any {
+	var entries []any
+	for _, name := range names {
+		entries = append(entries, &types.PodData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "pod", Name: name}})
+	}
+	return entries
+}
+
+func TestSpool_ReplayInOrder(t *testing.T) {
+	dir := t.TempDir()
+	writer := &flakyWriter{down: true}
+
+	spool, err := NewSpool(writer, SpoolOptions{Dir: dir, MaxSize: 1 << 20, ReplayInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, batch := range [][]any{podBatch("a", "b"), podBatch("c")} {
+		if err := spool.WriteBatch(context.Background(), batch); err != nil {
+			t.Fatalf("Expected entries to be spooled, got %v", err)
+		}
+	}
+	if pending := spool.Pending(); pending != 3 {
+		t.Fatalf("Expected 3 pending entries, got %d", pending)
+	}
+
+	// Batches stay spooled across restarts
+	if err := spool.Close(); err != nil {
+		t.Fatalf("Expected no error on close, got %v", err)
+	}
+	spool, err = NewSpool(writer, SpoolOptions{Dir: dir, MaxSize: 1 << 20, ReplayInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer spool.Close()
+
+	// New batches queue behind spooled ones even when the writer has recovered
+	writer.setDown(false)
+	if err := spool.WriteBatch(context.Background(), podBatch("d")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if names, _ := writer.received(); len(names) != 0 {
+		t.Fatalf("Expected nothing to be sent before replay, got %v", names)
+	}
+
+	spool.replay()
+
+	names, resourceTypes := writer.received()
+	if len(names) != 4 || names[0] != "a" || names[1] != "b" || names[2] != "c" || names[3] != "d" {
+		t.Errorf("Expected entries replayed in order a, b, c, d, got %v", names)
+	}
+	for _, resourceType := range resourceTypes {
+		if resourceType != "pod" {
+			t.Errorf("Expected replayed entries to keep their metadata, got resource type '%s'", resourceType)
+		}
+	}
+	if pending := spool.Pending(); pending != 0 {
+		t.Errorf("Expected empty spool after replay, got %d pending entries", pending)
+	}
+
+	// Once the spool is empty, batches are sent directly
+	if err := spool.WriteBatch(context.Background(), podBatch("e")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if names, _ := writer.received(); len(names) != 5 {
+		t.Errorf("Expected batch to be sent directly, got %v", names)
+	}
+}
+
+func TestSpool_DropOldest(t *testing.T) {
+	writer := &flakyWriter{down: true}
+
+	// Room for roughly two single-entry batches
+	batchSize := spoolBatchSize(t, podBatch("a"))
+	spool, err := NewSpool(writer, SpoolOptions{Name: "drop-oldest", Dir: t.TempDir(), MaxSize: 2*batchSize + batchSize/2, ReplayInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer spool.Close()
+
+	for _, name := range []string{"a", "b", "c"} {
+		if err := spool.WriteBatch(context.Background(), podBatch(name)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if dropped := spool.Dropped(); dropped != 1 {
+		t.Errorf("Expected 1 dropped entry, got %d", dropped)
+	}
+	if dropped := testutil.ToFloat64(metrics.SpoolDroppedEntries.WithLabelValues("drop-oldest")); dropped != 1 {
+		t.Errorf("Expected 1 dropped entry in the metrics, got %v", dropped)
+	}
+
+	writer.setDown(false)
+	spool.replay()
+
+	names, _ := writer.received()
+	if len(names) != 2 || names[0] != "b" || names[1] != "c" {
+		t.Errorf("Expected the oldest batch to be dropped, got %v", names)
+	}
+}
+
+func TestSpool_PartialWrites(t *testing.T) {
+	writer := &flakyWriter{down: true, partial: 1}
+
+	spool, err := NewSpool(writer, SpoolOptions{Dir: t.TempDir(), MaxSize: 1 << 20, ReplayInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer spool.Close()
+
+	// Only the entries that were not sent are spooled
+	if err := spool.WriteBatch(context.Background(), podBatch("a", "b", "c")); err != nil {
+		t.Fatalf("Expected entries to be spooled, got %v", err)
+	}
+	if pending := spool.Pending(); pending != 2 {
+		t.Fatalf("Expected 2 pending entries, got %d", pending)
+	}
+
+	// A replay that fails part way keeps only the entries that are still unsent
+	spool.replay()
+	if pending := spool.Pending(); pending != 1 {
+		t.Fatalf("Expected 1 pending entry, got %d", pending)
+	}
+
+	writer.setDown(false)
+	spool.replay()
+
+	names, _ := writer.received()
+	if len(names) != 3 || names[0] != "a" || names[1] != "b" || names[2] != "c" {
+		t.Errorf("Expected each entry to be sent once in order a, b, c, got %v", names)
+	}
+	if pending := spool.Pending(); pending != 0 {
+		t.Errorf("Expected empty spool after replay, got %d pending entries", pending)
+	}
+}
+
+// spoolBatchSize returns the size of a batch once spooled
+func spoolBatchSize(t *testing.T, batch []any) int64 {
+	t.Helper()
+
+	spool, err := NewSpool(&flakyWriter{down: true}, SpoolOptions{Dir: t.TempDir(), MaxSize: 1 << 20, ReplayInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer spool.Close()
+
+	if err := spool.WriteBatch(context.Background(), batch); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return spool.size
+}
@@ -0,0 +1,268 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+// Syslog transports
+const (
+	SyslogNetworkUDP = "udp"
+	SyslogNetworkTCP = "tcp"
+	SyslogNetworkTLS = "tls"
+)
+
+const (
+	syslogAppName         = "kube-state-logs"
+	syslogSeverityInfo    = 6
+	syslogFacilityLocal0  = 16
+	syslogDefaultSDID     = "kube@32473"
+	syslogMaxMsgIDLength  = 32
+	syslogMaxHostLength   = 255
+	syslogNilValue        = "-"
+	syslogTimestampFormat = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+// syslogSDEscaper escapes structured data parameter values as required by RFC 5424
+var syslogSDEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// SyslogOptions configures a SyslogSink
+type SyslogOptions struct {
+	Network     string        // SyslogNetworkUDP, SyslogNetworkTCP or SyslogNetworkTLS
+	Address     string        // host:port of the syslog receiver
+	Facility    int           // Syslog facility code, e.g. 16 for local0
+	Hostname    string        // HOSTNAME field, usually the cluster name
+	SDID        string        // ID of the structured data element holding the entry metadata
+	Encoder     Encoder       // Encoder for the message, JSON if nil
+	TLS         *tls.Config   // TLS client configuration for SyslogNetworkTLS
+	DialTimeout time.Duration // Timeout for connecting to the receiver
+	Timeout     time.Duration // Timeout for writing a message
+	Retry       RetryOptions
+}
+
+// SyslogSink sends entries as RFC 5424 syslog messages over UDP, TCP or TLS. The entry
+// metadata is sent as structured data and the encoded entry as the message. Stream
+// transports use octet-counting framing (RFC 6587) and reconnect after write failures.
+type SyslogSink struct {
+	opts SyslogOptions
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink creates a SyslogSink. The connection is established on first use.
+func NewSyslogSink(opts SyslogOptions) (*SyslogSink, error) {
+	if opts.Address == "" {
+		return nil, fmt.Errorf("address is required")
+	}
+	switch opts.Network {
+	case SyslogNetworkUDP, SyslogNetworkTCP, SyslogNetworkTLS:
+	default:
+		return nil, fmt.Errorf("unknown syslog network '%s'", opts.Network)
+	}
+	if opts.Facility < 0 || opts.Facility > 23 {
+		return nil, fmt.Errorf("syslog facility must be between 0 and 23, got %d", opts.Facility)
+	}
+	if opts.Hostname == "" {
+		opts.Hostname, _ = os.Hostname()
+	}
+	if opts.SDID == "" {
+		opts.SDID = syslogDefaultSDID
+	}
+	if opts.Encoder == nil {
+		opts.Encoder = jsonEncoder{}
+	}
+
+	return &SyslogSink{opts: opts}, nil
+}
+
+// newSyslogSink creates a SyslogSink from a sink's network, address, facility, hostname,
+// sd-id, output-format, TLS, dial-timeout, timeout and retry options
+func newSyslogSink(cfg config.SinkConfig) (*SyslogSink, error) {
+	facility, err := cfg.IntOption("facility", syslogFacilityLocal0)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := tlsConfigFromOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	dialTimeout, err := cfg.DurationOption("dial-timeout", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := cfg.DurationOption("timeout", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	retry, err := RetryOptionsFromConfig(cfg, DefaultRetryOptions())
+	if err != nil {
+		return nil, err
+	}
+	encoder, err := encoderFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSyslogSink(SyslogOptions{
+		Network:     cfg.Option("network", SyslogNetworkTCP),
+		Address:     cfg.Option("address", ""),
+		Facility:    facility,
+		Hostname:    cfg.Option("hostname", cfg.Option("cluster-name", "")),
+		SDID:        cfg.Option("sd-id", syslogDefaultSDID),
+		Encoder:     encoder,
+		TLS:         tlsConfig,
+		DialTimeout: dialTimeout,
+		Timeout:     timeout,
+		Retry:       retry,
+	})
+}
+
+// WriteBatch sends each entry as a syslog message. After a failure the sink reconnects
+// and resumes with the message that failed.
+func (s *SyslogSink) WriteBatch(ctx context.Context, entries []any) error {
+	messages := make([][]byte, 0, len(entries))
+	for _, entry := range entries {
+		message, err := s.format(entry, time.Now())
+		if err != nil {
+			return err
+		}
+		messages = append(messages, message)
+	}
+
+	sent := 0
+	return s.opts.Retry.Do(ctx, func() error {
+		for sent < len(messages) {
+			if err := s.write(messages[sent]); err != nil {
+				return retryable(err, 0)
+			}
+			sent++
+		}
+		return nil
+	})
+}
+
+// Close closes the connection to the receiver
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// format renders an entry as an RFC 5424 message
+func (s *SyslogSink) format(entry any, now time.Time) ([]byte, error) {
+	var payload bytes.Buffer
+	if err := s.opts.Encoder.Encode(&payload, entry); err != nil {
+		return nil, err
+	}
+
+	metadata, _ := types.MetadataOf(entry)
+	timestamp := metadata.Timestamp
+	if timestamp.IsZero() {
+		timestamp = now
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "<%d>1 %s %s %s %d %s ",
+		s.opts.Facility*8+syslogSeverityInfo,
+		timestamp.UTC().Format(syslogTimestampFormat),
+		syslogHeaderField(s.opts.Hostname, syslogMaxHostLength),
+		syslogAppName,
+		os.Getpid(),
+		syslogHeaderField(metadata.ResourceType, syslogMaxMsgIDLength),
+	)
+
+	message.WriteString("[" + s.opts.SDID)
+	for _, param := range [][2]string{
+		{"resourceType", metadata.ResourceType},
+		{"namespace", metadata.Namespace},
+		{"name", metadata.Name},
+		{"eventType", metadata.EventType},
+	} {
+		if param[1] != "" {
+			fmt.Fprintf(&message, ` %s="%s"`, param[0], syslogSDEscaper.Replace(param[1]))
+		}
+	}
+	message.WriteString("] ")
+	message.Write(payload.Bytes())
+
+	return []byte(message.String()), nil
+}
+
+// write sends a message, connecting first if needed. The connection is dropped on
+// failure so the next write reconnects.
+func (s *SyslogSink) write(message []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		conn, err := s.dial()
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog receiver %s: %w", s.opts.Address, err)
+		}
+		s.conn = conn
+	}
+
+	// Stream transports frame each message with its length (octet counting)
+	frame := message
+	if s.opts.Network != SyslogNetworkUDP {
+		frame = append([]byte(strconv.Itoa(len(message))+" "), message...)
+	}
+
+	if s.opts.Timeout > 0 {
+		s.conn.SetWriteDeadline(time.Now().Add(s.opts.Timeout))
+	}
+	if _, err := s.conn.Write(frame); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return fmt.Errorf("failed to write to syslog receiver %s: %w", s.opts.Address, err)
+	}
+	return nil
+}
+
+// dial connects to the receiver with the configured transport
+func (s *SyslogSink) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: s.opts.DialTimeout}
+	switch s.opts.Network {
+	case SyslogNetworkTLS:
+		return tls.DialWithDialer(dialer, "tcp", s.opts.Address, s.opts.TLS)
+	default:
+		return dialer.Dial(s.opts.Network, s.opts.Address)
+	}
+}
+
+// syslogHeaderField returns a header field limited to printable ASCII without spaces,
+// or the nil value if it is empty
+func syslogHeaderField(value string, maxLength int) string {
+	field := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, value)
+
+	if len(field) > maxLength {
+		field = field[:maxLength]
+	}
+	if field == "" {
+		return syslogNilValue
+	}
+	return field
+}
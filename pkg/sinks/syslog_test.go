@@ -0,0 +1,112 @@
+package sinks
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+func TestSyslogSink_Format(t *testing.T) {
+	sink, err := NewSyslogSink(SyslogOptions{
+		Network:  SyslogNetworkUDP,
+		Address:  "127.0.0.1:514",
+		Facility: syslogFacilityLocal0,
+		Hostname: "test cluster",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entry := &types.RoleData{LogEntryMetadata: types.LogEntryMetadata{
+		Timestamp:    time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		ResourceType: "role",
+		Namespace:    "default",
+		Name:         `odd"name]`,
+		EventType:    "update",
+	}}
+
+	message, err := sink.format(entry, time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	pattern := regexp.MustCompile(`^<134>1 2024-01-15T10:30:00\.000000Z testcluster kube-state-logs \d+ role ` +
+		`\[kube@32473 resourceType="role" namespace="default" name="odd\\"name\\]" eventType="update"\] \{.*\}$`)
+	if !pattern.Match(message) {
+		t.Errorf("Unexpected message format: %s", message)
+	}
+}
+
+func TestSyslogSink_TCPFraming(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		for {
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(length))
+			if err != nil {
+				return
+			}
+			message := make([]byte, n)
+			if _, err := io.ReadFull(reader, message); err != nil {
+				return
+			}
+			received <- string(message)
+		}
+	}()
+
+	sink, err := NewSyslogSink(SyslogOptions{
+		Network:     SyslogNetworkTCP,
+		Address:     listener.Addr().String(),
+		Facility:    syslogFacilityLocal0,
+		DialTimeout: time.Second,
+		Timeout:     time.Second,
+		Retry:       RetryOptions{MaxRetries: 1, InitialBackoff: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer sink.Close()
+
+	entries := []any{
+		&types.ClusterRoleData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "clusterrole", Name: "admin"}},
+		&types.ClusterRoleData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "clusterrole", Name: "edit"}},
+	}
+	if err := sink.WriteBatch(context.Background(), entries); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, name := range []string{"admin", "edit"} {
+		select {
+		case message := <-received:
+			if !strings.Contains(message, `name="`+name+`"`) || !strings.HasSuffix(message, "}") {
+				t.Errorf("Expected complete message for %s, got %s", name, message)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for message for %s", name)
+		}
+	}
+}
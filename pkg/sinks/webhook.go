@@ -0,0 +1,219 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go.goms.io/aks/kube-state-logs/pkg/config"
+)
+
+// Webhook body formats
+const (
+	WebhookFormatJSON   = "json"   // A JSON array of entries
+	WebhookFormatNDJSON = "ndjson" // One entry per line, JSON unless another Encoder is set
+	WebhookFormatSingle = "single" // One JSON entry per request
+)
+
+// WebhookOptions configures a WebhookSink
+type WebhookOptions struct {
+	URL             string            // Endpoint that receives the batches
+	Method          string            // HTTP method, POST by default
+	Format          string            // WebhookFormatJSON, WebhookFormatNDJSON or WebhookFormatSingle
+	ContentType     string            // Overrides the content type of the format
+	Encoder         Encoder           // Encoder for the lines of WebhookFormatNDJSON, JSON if nil
+	Headers         map[string]string // Extra request headers
+	BearerTokenFile string            // File holding a bearer token, read before every request so rotated tokens are picked up
+	TLS             *tls.Config       // TLS client configuration
+	Timeout         time.Duration     // Timeout for a single request
+	Retry           RetryOptions
+}
+
+// WebhookSink sends batches of entries to an HTTP endpoint
+type WebhookSink struct {
+	opts   WebhookOptions
+	client *http.Client
+}
+
+// NewWebhookSink creates a WebhookSink
+func NewWebhookSink(opts WebhookOptions) (*WebhookSink, error) {
+	if opts.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	if opts.Method == "" {
+		opts.Method = http.MethodPost
+	}
+	switch opts.Format {
+	case WebhookFormatJSON, WebhookFormatNDJSON, WebhookFormatSingle:
+	default:
+		return nil, fmt.Errorf("unknown webhook format '%s'", opts.Format)
+	}
+	if opts.Encoder == nil {
+		opts.Encoder = jsonEncoder{}
+	}
+	if _, isJSON := opts.Encoder.(jsonEncoder); !isJSON && opts.Format != WebhookFormatNDJSON {
+		return nil, fmt.Errorf("webhook format '%s' only supports JSON entries", opts.Format)
+	}
+
+	return &WebhookSink{
+		opts: opts,
+		client: &http.Client{
+			Timeout:   opts.Timeout,
+			Transport: &http.Transport{TLSClientConfig: opts.TLS, Proxy: http.ProxyFromEnvironment},
+		},
+	}, nil
+}
+
+// newWebhookSink creates a WebhookSink from a sink's url, method, format, content-type,
+// output-format, headers, bearer-token-file, TLS, timeout and retry options
+func newWebhookSink(cfg config.SinkConfig) (*WebhookSink, error) {
+	headers, err := parsePairs(cfg.Option("headers", ""))
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := tlsConfigFromOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := cfg.DurationOption("timeout", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	retry, err := RetryOptionsFromConfig(cfg, DefaultRetryOptions())
+	if err != nil {
+		return nil, err
+	}
+	encoder, err := encoderFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// CloudEvents are sent in the batched or structured content mode of the HTTP binding
+	format := cfg.Option("format", WebhookFormatJSON)
+	contentType := ""
+	if cfg.Option("envelope", EnvelopeNone) == EnvelopeCloudEvents {
+		switch format {
+		case WebhookFormatJSON:
+			contentType = CloudEventsBatchContentType
+		case WebhookFormatSingle:
+			contentType = CloudEventsContentType
+		}
+	}
+
+	return NewWebhookSink(WebhookOptions{
+		URL:             cfg.Option("url", ""),
+		Method:          strings.ToUpper(cfg.Option("method", http.MethodPost)),
+		Format:          format,
+		ContentType:     cfg.Option("content-type", contentType),
+		Encoder:         encoder,
+		Headers:         headers,
+		BearerTokenFile: cfg.Option("bearer-token-file", ""),
+		TLS:             tlsConfig,
+		Timeout:         timeout,
+		Retry:           retry,
+	})
+}
+
+// WriteBatch sends entries in a single request, or one request per entry with
+// WebhookFormatSingle, retrying on throttling and server errors. With WebhookFormatSingle,
+// retries resume with the entry that failed, and a failed batch only reports the entries
+// that were not sent.
+func (s *WebhookSink) WriteBatch(ctx context.Context, entries []any) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if s.opts.Format == WebhookFormatSingle {
+		bodies := make([][]byte, 0, len(entries))
+		for _, entry := range entries {
+			body, err := json.Marshal(entry)
+			if err != nil {
+				return fmt.Errorf("failed to marshal entry of type %T: %w", entry, err)
+			}
+			bodies = append(bodies, body)
+		}
+
+		sent := 0
+		err := s.opts.Retry.Do(ctx, func() error {
+			for sent < len(bodies) {
+				if err := s.send(bodies[sent], "application/json"); err != nil {
+					return err
+				}
+				sent++
+			}
+			return nil
+		})
+		if err != nil && sent > 0 {
+			return partialWrite(err, entries[sent:])
+		}
+		return err
+	}
+
+	body, contentType, err := s.encode(entries)
+	if err != nil {
+		return err
+	}
+	return s.opts.Retry.Do(ctx, func() error {
+		return s.send(body, contentType)
+	})
+}
+
+// send sends a request body, with the configured content type if one is set
+func (s *WebhookSink) send(body []byte, contentType string) error {
+	if s.opts.ContentType != "" {
+		contentType = s.opts.ContentType
+	}
+
+	req, err := http.NewRequest(s.opts.Method, s.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for key, value := range s.opts.Headers {
+		req.Header.Set(key, value)
+	}
+	if s.opts.BearerTokenFile != "" {
+		token, err := os.ReadFile(s.opts.BearerTokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read bearer token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return retryable(fmt.Errorf("failed to send webhook request: %w", err), 0)
+	}
+	defer resp.Body.Close()
+
+	return checkHTTPResponse(resp)
+}
+
+// encode serializes entries in the configured format
+func (s *WebhookSink) encode(entries []any) ([]byte, string, error) {
+	if s.opts.Format == WebhookFormatJSON {
+		body, err := json.Marshal(entries)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal entries: %w", err)
+		}
+		return body, "application/json", nil
+	}
+
+	var body bytes.Buffer
+	for _, entry := range entries {
+		if err := s.opts.Encoder.Encode(&body, entry); err != nil {
+			return nil, "", err
+		}
+		body.WriteByte('\n')
+	}
+	if _, isJSON := s.opts.Encoder.(jsonEncoder); !isJSON {
+		return body.Bytes(), "text/plain; charset=utf-8", nil
+	}
+	return body.Bytes(), "application/x-ndjson", nil
+}
@@ -0,0 +1,178 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.goms.io/aks/kube-state-logs/pkg/types"
+)
+
+func TestWebhookSink_WriteBatch(t *testing.T) {
+	tests := []struct {
+		name                string
+		format              string
+		expectedContentType string
+	}{
+		{name: "json array", format: WebhookFormatJSON, expectedContentType: "application/json"},
+		{name: "ndjson", format: WebhookFormatNDJSON, expectedContentType: "application/x-ndjson"},
+	}
+
+	entries := []any{
+		&types.ServiceData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "service", Name: "api"}},
+		&types.ServiceData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "service", Name: "web"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenFile := filepath.Join(t.TempDir(), "token")
+			if err := os.WriteFile(tokenFile, []byte("first-token\n"), 0o600); err != nil {
+				t.Fatalf("Failed to write token: %v", err)
+			}
+
+			var authorizations []string
+			var names []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorizations = append(authorizations, r.Header.Get("Authorization"))
+				if len(authorizations) == 1 {
+					// Rotate the token while the first attempt fails
+					os.WriteFile(tokenFile, []byte("second-token"), 0o600)
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				if r.Header.Get("Content-Type") != tt.expectedContentType || r.Header.Get("X-Source") != "kube-state-logs" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				body, _ := io.ReadAll(r.Body)
+				var received []map[string]any
+				if tt.format == WebhookFormatJSON {
+					json.Unmarshal(body, &received)
+				} else {
+					for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+						var entry map[string]any
+						json.Unmarshal([]byte(line), &entry)
+						received = append(received, entry)
+					}
+				}
+				for _, entry := range received {
+					names = append(names, entry["name"].(string))
+				}
+			}))
+			defer server.Close()
+
+			sink, err := NewWebhookSink(WebhookOptions{
+				URL:             server.URL,
+				Format:          tt.format,
+				Headers:         map[string]string{"X-Source": "kube-state-logs"},
+				BearerTokenFile: tokenFile,
+				Timeout:         5 * time.Second,
+				Retry:           RetryOptions{MaxRetries: 2, InitialBackoff: time.Millisecond},
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if err := sink.WriteBatch(context.Background(), entries); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			expectedAuthorizations := []string{"Bearer first-token", "Bearer second-token"}
+			if strings.Join(authorizations, ",") != strings.Join(expectedAuthorizations, ",") {
+				t.Errorf("Expected authorizations %v, got %v", expectedAuthorizations, authorizations)
+			}
+			if strings.Join(names, ",") != "api,web" {
+				t.Errorf("Expected entries api and web, got %v", names)
+			}
+		})
+	}
+}
+
+func TestWebhookSink_WriteBatch_SingleResumes(t *testing.T) {
+	var names []string
+	failed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var entry map[string]any
+		json.NewDecoder(r.Body).Decode(&entry)
+		name := entry["name"].(string)
+		// The second entry fails once
+		if name == "web" && !failed {
+			failed = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		names = append(names, name)
+	}))
+	defer server.Close()
+
+	sink, err := NewWebhookSink(WebhookOptions{
+		URL:    server.URL,
+		Format: WebhookFormatSingle,
+		Retry:  RetryOptions{MaxRetries: 2, InitialBackoff: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entries := []any{
+		&types.ServiceData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "service", Name: "api"}},
+		&types.ServiceData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "service", Name: "web"}},
+		&types.ServiceData{LogEntryMetadata: types.LogEntryMetadata{ResourceType: "service", Name: "db"}},
+	}
+	if err := sink.WriteBatch(context.Background(), entries); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The retry resumes with the failed entry instead of resending the first one
+	if strings.Join(names, ",") != "api,web,db" {
+		t.Errorf("Expected entries api, web and db once each, got %v", names)
+	}
+
+	// Once the retries are exhausted, only the unsent entries are reported
+	failed = false
+	sink.opts.Retry.MaxRetries = 0
+	err = sink.WriteBatch(context.Background(), entries)
+	if !isRetryable(err) {
+		t.Fatalf("Expected a retryable error, got %v", err)
+	}
+	if unsent := unsentEntries(err, entries); len(unsent) != 2 || unsent[0] != entries[1] {
+		t.Errorf("Expected the last 2 entries to be unsent, got %d", len(unsent))
+	}
+}
+
+func TestWebhookSink_RetryBudget(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sink, err := NewWebhookSink(WebhookOptions{
+		URL:    server.URL,
+		Format: WebhookFormatJSON,
+		Retry: RetryOptions{
+			MaxRetries:     100,
+			InitialBackoff: 20 * time.Millisecond,
+			MaxElapsed:     50 * time.Millisecond,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := sink.WriteBatch(context.Background(), []any{&types.ServiceData{}}); err == nil {
+		t.Error("Expected error once the retry budget is exhausted")
+	}
+	if attempts < 2 || attempts > 3 {
+		t.Errorf("Expected 2 or 3 attempts within the retry budget, got %d", attempts)
+	}
+}
@@ -0,0 +1,146 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterSink writes entries as lines to an io.Writer, as JSON unless another Encoder is
+// set. It is safe for concurrent use and writes each entry or batch with a single call
+// to the writer.
+type WriterSink struct {
+	mu      sync.Mutex
+	writer  io.Writer
+	encoder Encoder
+	buffer  bytes.Buffer
+	closer  io.Closer
+}
+
+// rotator is implemented by writers that move to a new file, such as RotatingFile
+type rotator interface {
+	RotateIfNeeded(n int64) (bool, error)
+}
+
+// NewWriterSink creates a WriterSink. The writer is closed with the sink if it is an io.Closer.
+func NewWriterSink(w io.Writer) *WriterSink {
+	sink := &WriterSink{
+		writer:  w,
+		encoder: jsonEncoder{},
+	}
+	if closer, ok := w.(io.Closer); ok {
+		sink.closer = closer
+	}
+	return sink
+}
+
+// NewStdoutSink creates a sink that writes entries as JSON to stdout
+func NewStdoutSink() *WriterSink {
+	return &WriterSink{
+		writer:  os.Stdout,
+		encoder: jsonEncoder{},
+	}
+}
+
+// NewFileSink creates a sink that appends entries as JSON to a file
+func NewFileSink(path string) (*WriterSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	return NewWriterSink(file), nil
+}
+
+// SetEncoder sets the encoder used for entries written after it. Encoders with a header
+// do not write it again when the writer appends to a file that is not empty.
+func (s *WriterSink) SetEncoder(encoder Encoder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if resettable, ok := encoder.(resettableEncoder); ok && continuesOutput(s.writer) {
+		resettable.Continue()
+	}
+	s.encoder = encoder
+}
+
+// continuesOutput reports whether a writer appends to output left by a previous run,
+// such as a file that is not empty
+func continuesOutput(w io.Writer) bool {
+	switch w := w.(type) {
+	case *RotatingFile:
+		return w.Size() > 0
+	case *os.File:
+		info, err := w.Stat()
+		return err == nil && info.Mode().IsRegular() && info.Size() > 0
+	default:
+		return false
+	}
+}
+
+// Log writes an entry as a line
+func (s *WriterSink) Log(entry any) error {
+	return s.WriteBatch(context.Background(), []any{entry})
+}
+
+// WriteBatch writes entries as lines. Entries that cannot be encoded are skipped
+// and reported in the returned error; the rest are still written.
+func (s *WriterSink) WriteBatch(ctx context.Context, entries []any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	encodeErr := s.encode(entries)
+
+	// Encoders with a header start over in a new file, so encode again after rotating
+	if encoder, ok := s.encoder.(resettableEncoder); ok {
+		if rotator, ok := s.writer.(rotator); ok {
+			rotated, err := rotator.RotateIfNeeded(int64(s.buffer.Len()))
+			if err != nil {
+				return fmt.Errorf("failed to rotate: %w", err)
+			}
+			if rotated {
+				encoder.Reset()
+				encodeErr = s.encode(entries)
+			}
+		}
+	}
+
+	if _, err := s.writer.Write(s.buffer.Bytes()); err != nil {
+		return fmt.Errorf("failed to write entries: %w", err)
+	}
+	return encodeErr
+}
+
+// encode encodes entries into the buffer, one per line
+func (s *WriterSink) encode(entries []any) error {
+	s.buffer.Reset()
+
+	var encodeErr error
+	for _, entry := range entries {
+		length := s.buffer.Len()
+		if err := s.encoder.Encode(&s.buffer, entry); err != nil {
+			s.buffer.Truncate(length)
+			encodeErr = fmt.Errorf("failed to encode entry of type %T: %w", entry, err)
+			continue
+		}
+		s.buffer.WriteByte('\n')
+	}
+	return encodeErr
+}
+
+// Close closes the underlying writer, if it can be closed
+func (s *WriterSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
@@ -17,6 +17,44 @@ type LogEntryMetadata struct {
 	Annotations      map[string]string `json:"annotations"`
 	CreatedByKind    string            `json:"createdByKind"`
 	CreatedByName    string            `json:"createdByName"`
+	EventType        string            `json:"eventType,omitempty"`
+	Deleted          bool              `json:"deleted,omitempty"`
+	DeletedTimestamp *time.Time        `json:"deletedTimestamp,omitempty"`
+}
+
+// Entry is implemented by every log entry type and exposes its common metadata
+type Entry interface {
+	GetMetadata() LogEntryMetadata
+}
+
+// MetadataOf returns the common metadata of a log entry, if it has any
+func MetadataOf(entry any) (LogEntryMetadata, bool) {
+	e, ok := entry.(Entry)
+	if !ok {
+		return LogEntryMetadata{}, false
+	}
+	return e.GetMetadata(), true
+}
+
+// GetMetadata returns the common metadata of the entry
+func (m LogEntryMetadata) GetMetadata() LogEntryMetadata {
+	return m
+}
+
+// SetEventType tags the entry with the informer event that produced it
+func (m *LogEntryMetadata) SetEventType(eventType string) {
+	m.EventType = eventType
+}
+
+// SetTimestamp sets the time the entry was produced
+func (m *LogEntryMetadata) SetTimestamp(timestamp time.Time) {
+	m.Timestamp = timestamp
+}
+
+// MarkDeleted turns the entry into a tombstone for an object that was removed from the cluster
+func (m *LogEntryMetadata) MarkDeleted(deletedAt time.Time) {
+	m.Deleted = true
+	m.DeletedTimestamp = &deletedAt
 }
 
 // DeploymentData represents deployment-specific metrics (matching kube-state-metrics)
@@ -161,6 +199,44 @@ type ContainerData struct {
 	LastTerminatedExitCode  int32      `json:"lastTerminatedExitCode"`
 	LastTerminatedTimestamp *time.Time `json:"lastTerminatedTimestamp"`
 	StateStarted            *time.Time `json:"stateStarted"`
+
+	// Informer event that produced the entry (event-driven mode and tombstones only)
+	EventType string `json:"eventType,omitempty"`
+
+	// Tombstone details, set once the owning pod is deleted
+	Deleted          bool       `json:"deleted,omitempty"`
+	DeletedTimestamp *time.Time `json:"deletedTimestamp,omitempty"`
+}
+
+// GetMetadata returns the common metadata of the entry, with the owning pod as creator
+func (c ContainerData) GetMetadata() LogEntryMetadata {
+	return LogEntryMetadata{
+		Timestamp:        c.Timestamp,
+		ResourceType:     c.ResourceType,
+		Name:             c.Name,
+		Namespace:        c.Namespace,
+		CreatedByKind:    "Pod",
+		CreatedByName:    c.PodName,
+		EventType:        c.EventType,
+		Deleted:          c.Deleted,
+		DeletedTimestamp: c.DeletedTimestamp,
+	}
+}
+
+// SetEventType tags the entry with the informer event that produced it
+func (c *ContainerData) SetEventType(eventType string) {
+	c.EventType = eventType
+}
+
+// SetTimestamp sets the time the entry was produced
+func (c *ContainerData) SetTimestamp(timestamp time.Time) {
+	c.Timestamp = timestamp
+}
+
+// MarkDeleted turns the entry into a tombstone for a container whose pod was removed from the cluster
+func (c *ContainerData) MarkDeleted(deletedAt time.Time) {
+	c.Deleted = true
+	c.DeletedTimestamp = &deletedAt
 }
 
 // ServiceData represents service-specific metrics (matching kube-state-metrics)
@@ -546,6 +622,42 @@ type EndpointPortData struct {
 	Port     int32  `json:"port"`
 }
 
+// EndpointSliceData represents endpointslice-specific metrics (matching kube-state-metrics)
+type EndpointSliceData struct {
+	LogEntryMetadata
+	// EndpointSlice specific
+	ServiceName string                      `json:"serviceName"`
+	AddressType string                      `json:"addressType"`
+	ManagedBy   string                      `json:"managedBy"`
+	Endpoints   []EndpointSliceEndpointData `json:"endpoints"`
+	Ports       []EndpointPortData          `json:"ports"`
+
+	// Endpoint counts by condition
+	EndpointsCount            int `json:"endpointsCount"`
+	ReadyEndpointsCount       int `json:"readyEndpointsCount"`
+	ServingEndpointsCount     int `json:"servingEndpointsCount"`
+	TerminatingEndpointsCount int `json:"terminatingEndpointsCount"`
+}
+
+// EndpointSliceEndpointData represents a single endpoint within an endpoint slice
+type EndpointSliceEndpointData struct {
+	Addresses     []string `json:"addresses"`
+	Hostname      string   `json:"hostname"`
+	NodeName      string   `json:"nodeName"`
+	Zone          string   `json:"zone"`
+	TargetRefKind string   `json:"targetRefKind"`
+	TargetRef     string   `json:"targetRef"`
+
+	// Conditions
+	Ready       *bool `json:"ready"`
+	Serving     *bool `json:"serving"`
+	Terminating *bool `json:"terminating"`
+
+	// Topology aware routing hints
+	HintsForZones []string `json:"hintsForZones"`
+	HintsForNodes []string `json:"hintsForNodes"`
+}
+
 // PersistentVolumeData represents persistentvolume-specific metrics (matching kube-state-metrics)
 type PersistentVolumeData struct {
 	LogEntryMetadata
@@ -882,3 +994,30 @@ type ValidatingWebhookConfigurationData struct {
 	// ValidatingWebhookConfiguration specific
 	Webhooks []WebhookData `json:"webhooks"`
 }
+
+// EventData represents a Kubernetes event (events.k8s.io/v1)
+type EventData struct {
+	LogEntryMetadata
+	// Object the event is about
+	InvolvedObjectKind       string `json:"involvedObjectKind"`
+	InvolvedObjectAPIVersion string `json:"involvedObjectAPIVersion"`
+	InvolvedObjectNamespace  string `json:"involvedObjectNamespace"`
+	InvolvedObjectName       string `json:"involvedObjectName"`
+	InvolvedObjectUID        string `json:"involvedObjectUID"`
+	InvolvedObjectFieldPath  string `json:"involvedObjectFieldPath"`
+
+	// Event details
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Action  string `json:"action"`
+	Count   int32  `json:"count"`
+
+	// Event timing
+	FirstTimestamp *time.Time `json:"firstTimestamp"`
+	LastTimestamp  *time.Time `json:"lastTimestamp"`
+
+	// Event source
+	ReportingController string `json:"reportingController"`
+	ReportingInstance   string `json:"reportingInstance"`
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"go.goms.io/aks/kube-state-logs/pkg/interfaces"
+)
+
+// Event types used to tag entries emitted from informer events
+const (
+	EventTypeCreate = "create"
+	EventTypeUpdate = "update"
+	EventTypeDelete = "delete"
+)
+
+// EntryFunc converts an object from an informer store into log entries.
+// Entries must be pointers so they can be tagged with the event that produced them.
+type EntryFunc func(obj any) []any
+
+// eventEntry is implemented by log entries that can be emitted from informer events
+type eventEntry interface {
+	SetEventType(eventType string)
+	SetTimestamp(timestamp time.Time)
+	MarkDeleted(deletedAt time.Time)
+}
+
+// NewEntryFunc adapts a handler's typed createLogEntry method into an EntryFunc
+func NewEntryFunc[T any, E any](create func(T) E) EntryFunc {
+	return func(obj any) []any {
+		typed, ok := obj.(T)
+		if !ok {
+			return nil
+		}
+		entry := create(typed)
+		return []any{&entry}
+	}
+}
+
+// RegisterEventLogging registers add and update handlers on the informer that log entries
+// as soon as an object changes, tagged with the corresponding event type. Objects from the
+// initial list already existed, so they are left to the ticks rather than logged as created.
+// Deletes are covered by RegisterDeletionLogging.
+func RegisterEventLogging(informer cache.SharedIndexInformer, logger interfaces.Logger, entryFunc EntryFunc, namespaces []string) error {
+	if informer == nil {
+		return fmt.Errorf("informer is not set up")
+	}
+	if entryFunc == nil {
+		return fmt.Errorf("no entry function configured")
+	}
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj any, isInInitialList bool) {
+			if isInInitialList {
+				return
+			}
+			logEventEntries(logger, entryFunc, namespaces, obj, EventTypeCreate)
+		},
+		UpdateFunc: func(oldObj, newObj any) {
+			// Skip periodic resyncs where nothing changed
+			if sameResourceVersion(oldObj, newObj) {
+				return
+			}
+			logEventEntries(logger, entryFunc, namespaces, newObj, EventTypeUpdate)
+		},
+	})
+	return err
+}
+
+// RegisterDeletionLogging registers a delete handler on the informer that logs a final
+// tombstone entry with the object's last known state, marked as deleted
+func RegisterDeletionLogging(informer cache.SharedIndexInformer, logger interfaces.Logger, entryFunc EntryFunc, namespaces []string) error {
+	if informer == nil {
+		return fmt.Errorf("informer is not set up")
+	}
+	if entryFunc == nil {
+		return fmt.Errorf("no entry function configured")
+	}
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj any) {
+			logEventEntries(logger, entryFunc, namespaces, UnwrapTombstone(obj), EventTypeDelete)
+		},
+	})
+	return err
+}
+
+// UnwrapTombstone returns the last known state of an object from a delete notification,
+// which is wrapped in a DeletedFinalStateUnknown when the watch missed the actual delete
+func UnwrapTombstone(obj any) any {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+	return obj
+}
+
+// logEventEntries converts an object into entries and logs them with the given event type
+func logEventEntries(logger interfaces.Logger, entryFunc EntryFunc, namespaces []string, obj any, eventType string) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		klog.V(2).Infof("Ignoring %s event for object of type %T: %v", eventType, obj, err)
+		return
+	}
+
+	// Cluster-scoped objects are not subject to the namespace filter
+	if namespace := accessor.GetNamespace(); namespace != "" && !ShouldIncludeNamespace(namespaces, namespace) {
+		return
+	}
+
+	now := time.Now()
+	for _, entry := range entryFunc(obj) {
+		if tagged, ok := entry.(eventEntry); ok {
+			tagged.SetEventType(eventType)
+			tagged.SetTimestamp(now)
+			if eventType == EventTypeDelete {
+				tagged.MarkDeleted(now)
+			}
+		}
+		if err := logger.Log(entry); err != nil {
+			klog.Errorf("Failed to log %s event entry: %v", eventType, err)
+		}
+	}
+}
+
+// sameResourceVersion reports whether two versions of an object share a resource version
+func sameResourceVersion(oldObj, newObj any) bool {
+	oldAccessor, err := meta.Accessor(oldObj)
+	if err != nil {
+		return false
+	}
+	newAccessor, err := meta.Accessor(newObj)
+	if err != nil {
+		return false
+	}
+	return oldAccessor.GetResourceVersion() != "" && oldAccessor.GetResourceVersion() == newAccessor.GetResourceVersion()
+}
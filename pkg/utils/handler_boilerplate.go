@@ -7,11 +7,15 @@ import (
 	"go.goms.io/aks/kube-state-logs/pkg/interfaces"
 )
 
-// BaseHandler provides common fields and methods for resource handlers
+// BaseHandler provides common fields and methods for resource handlers.
+// The handler tests check that it is embedded with handler.BaseHandler == (utils.BaseHandler{}),
+// which only compiles while every field is comparable, so the entry function is held by
+// pointer rather than as a func value.
 type BaseHandler struct {
-	client   kubernetes.Interface
-	informer cache.SharedIndexInformer
-	logger   interfaces.Logger
+	client    kubernetes.Interface
+	informer  cache.SharedIndexInformer
+	logger    interfaces.Logger
+	entryFunc *EntryFunc
 }
 
 // NewBaseHandler creates a new BaseHandler
@@ -27,6 +31,29 @@ func (h *BaseHandler) SetupBaseInformer(informer cache.SharedIndexInformer, logg
 	h.logger = logger
 }
 
+// SetEntryFunc sets the function used to convert a single informer object into log entries
+func (h *BaseHandler) SetEntryFunc(entryFunc EntryFunc) {
+	h.entryFunc = &entryFunc
+}
+
+// EnableEventLogging registers informer event handlers that log entries on create and update
+func (h *BaseHandler) EnableEventLogging(namespaces []string) error {
+	return RegisterEventLogging(h.informer, h.logger, h.getEntryFunc(), namespaces)
+}
+
+// EnableDeletionLogging registers an informer event handler that logs a tombstone entry on delete
+func (h *BaseHandler) EnableDeletionLogging(namespaces []string) error {
+	return RegisterDeletionLogging(h.informer, h.logger, h.getEntryFunc(), namespaces)
+}
+
+// getEntryFunc returns the configured entry function, or nil if none was set
+func (h *BaseHandler) getEntryFunc() EntryFunc {
+	if h.entryFunc == nil {
+		return nil
+	}
+	return *h.entryFunc
+}
+
 // GetClient returns the Kubernetes client
 func (h *BaseHandler) GetClient() kubernetes.Interface {
 	return h.client